@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jws"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+const testKeyPath = "docker/auth/test-key.pem"
+
+// runAuthToken - sign token for local runs against test key set.
+func runAuthToken(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("auth token", flag.ContinueOnError)
+
+	uid := fs.String("uid", "", "token subject uid")
+	keyPath := fs.String("key", testKeyPath, "PKCS1 PEM RSA private key")
+	kid := fs.String("kid", "local-test", "key id header")
+	audience := fs.String("aud", "", "audience claim, server requires it in jwks mode")
+	issuer := fs.String("iss", "", "issuer claim, server requires it in jwks mode")
+	ttl := fs.Duration("ttl", time.Hour, "token lifetime")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	if *uid == "" {
+		return errors.New(`"uid": cannot be empty`)
+	}
+
+	bb, err := os.ReadFile(*keyPath)
+	if err != nil {
+		return fmt.Errorf("read key: %w", err)
+	}
+
+	block, _ := pem.Decode(bb)
+	if block == nil {
+		return fmt.Errorf("no PEM data in %s", *keyPath)
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("parse key: %w", err)
+	}
+
+	now := time.Now()
+
+	token := jwt.New()
+	_ = token.Set("uid", *uid)
+	_ = token.Set(jwt.IssuedAtKey, now)
+	_ = token.Set(jwt.ExpirationKey, now.Add(*ttl))
+
+	if *audience != "" {
+		_ = token.Set(jwt.AudienceKey, *audience)
+	}
+
+	if *issuer != "" {
+		_ = token.Set(jwt.IssuerKey, *issuer)
+	}
+
+	headers := jws.NewHeaders()
+	_ = headers.Set(jws.KeyIDKey, *kid)
+
+	signed, err := jwt.Sign(token, jwa.RS256, key, jwt.WithHeaders(headers))
+	if err != nil {
+		return fmt.Errorf("sign token: %w", err)
+	}
+
+	fmt.Println(string(signed))
+
+	return nil
+}
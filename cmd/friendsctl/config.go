@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/daniilty/sharenote-friends/internal/kafka"
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+// ctlConfig - part of server config used by commands, each command validates what it uses.
+type ctlConfig struct {
+	Mongo mongo.Config
+	Kafka kafka.ConsumerConfig
+	Users struct {
+		GRPCAddr string `config:"users.grpc_addr" env:"USERS_GRPC_ADDR" usage:"users service address, used by export and friends show -names"`
+	}
+}
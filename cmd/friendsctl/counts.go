@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+// runCountsRecompute - rebuild denormalised friends and pending requests counters
+// from friends and requests collections.
+func runCountsRecompute(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("counts recompute", flag.ContinueOnError)
+
+	mutation := addMutationFlags(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	recompute, err := db.RecomputeCounts(ctx, false)
+	if err != nil {
+		return fmt.Errorf("compare counts: %w", err)
+	}
+
+	fmt.Printf("checked %d users, %d with wrong counts\n", recompute.Users, len(recompute.Fixed))
+
+	plan := []string{}
+
+	for _, fix := range recompute.Fixed {
+		plan = append(plan, fmt.Sprintf("%s: friends %d -> %d, incoming %d -> %d, outgoing %d -> %d",
+			fix.Actual.UID,
+			fix.Stored.Friends, fix.Actual.Friends,
+			fix.Stored.IncomingPending, fix.Actual.IncomingPending,
+			fix.Stored.OutgoingPending, fix.Actual.OutgoingPending))
+	}
+
+	// counts are compared again on apply, users changed meanwhile are fixed too
+	return mutation.apply(plan, func() error {
+		_, err := db.RecomputeCounts(ctx, true)
+
+		return err
+	})
+}
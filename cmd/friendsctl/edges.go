@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+const (
+	edgeTypeFriend  = "friend"
+	edgeTypeRequest = "request"
+)
+
+type edgeJSON struct {
+	Type string `json:"type"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// runEdgesDump - stream graph edges as JSON lines.
+func runEdgesDump(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("edges dump", flag.ContinueOnError)
+
+	edgeType := fs.String("type", "all", "friend, request or all")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	switch *edgeType {
+	case edgeTypeFriend, edgeTypeRequest, "all":
+	default:
+		return fmt.Errorf(`"type": unsupported value %q`, *edgeType)
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	enc := json.NewEncoder(os.Stdout)
+
+	write := func(t string) func(mongo.Edge) error {
+		return func(e mongo.Edge) error {
+			return enc.Encode(&edgeJSON{
+				Type: t,
+				From: e.From,
+				To:   e.To,
+			})
+		}
+	}
+
+	if *edgeType != edgeTypeRequest {
+		err = db.ForEachFriendEdge(ctx, write(edgeTypeFriend))
+		if err != nil {
+			return fmt.Errorf("dump friend edges: %w", err)
+		}
+	}
+
+	if *edgeType != edgeTypeFriend {
+		err = db.ForEachFriendRequestEdge(ctx, write(edgeTypeRequest))
+		if err != nil {
+			return fmt.Errorf("dump request edges: %w", err)
+		}
+	}
+
+	return nil
+}
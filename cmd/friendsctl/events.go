@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/kafka"
+	"github.com/daniilty/sharenote-friends/internal/users"
+	"go.uber.org/zap"
+)
+
+const replayGroupSuffix = "-replay"
+
+func runEventsReplay(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("events replay", flag.ContinueOnError)
+
+	topic := fs.String("topic", cfg.Kafka.Topic, "users events topic, defaults to kafka.topic")
+	groupID := fs.String("group", cfg.Kafka.GroupID+replayGroupSuffix, "replay consumer group, must differ from the live one")
+	fromOffset := fs.Int64("from-offset", -1, "replay every partition from offset")
+	fromTime := fs.String("from-time", "", "replay every partition from RFC3339 time")
+	dryRun := fs.Bool("dry-run", false, "report changes without applying them or committing offsets")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of handling one event")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	if *topic == "" {
+		return errors.New(`"topic": cannot be empty`)
+	}
+
+	if *groupID == replayGroupSuffix || *groupID == cfg.Kafka.GroupID {
+		return errors.New(`"group": must be set and differ from kafka.group_id`)
+	}
+
+	start := kafka.ReplayStart{
+		Offset: *fromOffset,
+	}
+
+	if *fromTime != "" {
+		if *fromOffset >= 0 {
+			return errors.New(`"from-offset" and "from-time" are mutually exclusive`)
+		}
+
+		start.Time, err = time.Parse(time.RFC3339, *fromTime)
+		if err != nil {
+			return fmt.Errorf(`"from-time": %w`, err)
+		}
+	}
+
+	err = cfg.Kafka.Config.Validate()
+	if err != nil {
+		return err
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	consumer, err := kafka.NewReplayConsumerImpl(ctx, *topic, *groupID, &cfg.Kafka.Config, start)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	logger, err := zap.NewProductionConfig().Build()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "replaying %d messages from %s\n", consumer.Lag(), *topic)
+
+	replayer := users.NewReplayer(logger.Sugar(), *timeout, db, consumer)
+
+	stats, err := replayer.Replay(ctx, os.Stdout, *dryRun)
+	if stats != nil {
+		fmt.Fprintf(os.Stdout, "processed: %d, applied: %d, skipped: %d, failed: %d, dry run: %t\n",
+			stats.Processed, stats.Applied, stats.Skipped, stats.Failed, *dryRun)
+	}
+
+	return err
+}
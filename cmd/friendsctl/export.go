@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// runExport - write user graph archive, same as export API.
+func runExport(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+
+	uid := fs.String("uid", "", "user id")
+	out := fs.String("out", "", `archive path, "-" for stdout, defaults to friends-export-<uid>.zip`)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	if *uid == "" {
+		return errors.New(`"uid": cannot be empty`)
+	}
+
+	if *out == "" {
+		*out = "friends-export-" + *uid + ".zip"
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	service, closeService, err := newService(ctx, cfg, db)
+	if err != nil {
+		return err
+	}
+	defer closeService()
+
+	var w io.Writer = os.Stdout
+
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	err = service.Export(ctx, *uid, w)
+	if err != nil {
+		// incomplete archive must not look like finished one
+		if *out != "-" {
+			os.Remove(*out)
+		}
+
+		return fmt.Errorf("export: %w", err)
+	}
+
+	if *out != "-" {
+		fmt.Fprintf(os.Stderr, "written %s\n", *out)
+	}
+
+	return nil
+}
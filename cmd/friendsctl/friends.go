@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+	"github.com/daniilty/sharenote-friends/internal/slice"
+)
+
+// runFriendsShow - print user friends, incoming and outgoing requests.
+func runFriendsShow(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("friends show", flag.ContinueOnError)
+
+	uid := fs.String("uid", "", "user id")
+	names := fs.Bool("names", false, "resolve names through users.grpc_addr")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	if *uid == "" {
+		return errors.New(`"uid": cannot be empty`)
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	friends, err := db.GetFriends(ctx, *uid)
+	if err != nil {
+		return fmt.Errorf("get friends: %w", err)
+	}
+
+	incoming, err := db.GetFriendRequests(ctx, *uid)
+	if err != nil {
+		return fmt.Errorf("get friend requests: %w", err)
+	}
+
+	outgoing, err := db.GetOutgoingFriendRequests(ctx, *uid)
+	if err != nil {
+		return fmt.Errorf("get outgoing friend requests: %w", err)
+	}
+
+	resolve := func(ids []string) ([]*core.User, error) {
+		uu := make([]*core.User, 0, len(ids))
+
+		for i := range ids {
+			uu = append(uu, &core.User{ID: ids[i]})
+		}
+
+		return uu, nil
+	}
+
+	if *names {
+		service, closeService, err := newService(ctx, cfg, db)
+		if err != nil {
+			return err
+		}
+		defer closeService()
+
+		resolve = func(ids []string) ([]*core.User, error) {
+			return service.GetUsers(ctx, ids)
+		}
+	}
+
+	sections := []struct {
+		title string
+		ids   []string
+	}{
+		{title: "friends", ids: friends.FriendIDs},
+		{title: "incoming requests", ids: incoming.FriendIDs},
+		{title: "outgoing requests", ids: outgoing},
+	}
+
+	for _, s := range sections {
+		uu, err := resolve(s.ids)
+		if err != nil {
+			return fmt.Errorf("resolve %s: %w", s.title, err)
+		}
+
+		fmt.Printf("%s (%d):\n", s.title, len(s.ids))
+
+		for _, u := range uu {
+			fmt.Printf("  %s\t%s\n", u.ID, u.Name)
+		}
+	}
+
+	// one-sided edges are left by partial writes and show up as ghost friends
+	for _, id := range friends.FriendIDs {
+		other, err := db.GetFriends(ctx, id)
+		if err != nil {
+			return fmt.Errorf("get friends of %s: %w", id, err)
+		}
+
+		if !slice.ContainsString(other.FriendIDs, *uid) {
+			fmt.Printf("warning: %s is not friends with %s, fix with \"friends remove\" or \"friends add\"\n", id, *uid)
+		}
+	}
+
+	return nil
+}
+
+// runFriendsAdd - make users friends bypassing friend request.
+func runFriendsAdd(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("friends add", flag.ContinueOnError)
+
+	uid := fs.String("uid", "", "user id")
+	friendID := fs.String("friend", "", "friend user id")
+	mutation := addMutationFlags(fs)
+
+	err := parsePairFlags(fs, args, uid, friendID)
+	if err != nil {
+		return err
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	plan, err := planFriendship(ctx, db, *uid, *friendID, true)
+	if err != nil {
+		return err
+	}
+
+	return mutation.apply(plan, func() error {
+		return db.ForceAddFriend(ctx, *uid, *friendID)
+	})
+}
+
+// runFriendsRemove - remove friendship edges in both directions.
+func runFriendsRemove(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("friends remove", flag.ContinueOnError)
+
+	uid := fs.String("uid", "", "user id")
+	friendID := fs.String("friend", "", "friend user id")
+	mutation := addMutationFlags(fs)
+
+	err := parsePairFlags(fs, args, uid, friendID)
+	if err != nil {
+		return err
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	plan, err := planFriendship(ctx, db, *uid, *friendID, false)
+	if err != nil {
+		return err
+	}
+
+	return mutation.apply(plan, func() error {
+		return db.ForceRemoveFriend(ctx, *uid, *friendID)
+	})
+}
+
+// runRequestsRemove - drop pending friend request.
+func runRequestsRemove(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("requests remove", flag.ContinueOnError)
+
+	from := fs.String("from", "", "request sender id")
+	to := fs.String("to", "", "request recipient id")
+	mutation := addMutationFlags(fs)
+
+	err := parsePairFlags(fs, args, from, to)
+	if err != nil {
+		return err
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	requests, err := db.GetFriendRequests(ctx, *to)
+	if err != nil {
+		return fmt.Errorf("get friend requests: %w", err)
+	}
+
+	plan := []string{}
+	if slice.ContainsString(requests.FriendIDs, *from) {
+		plan = append(plan, fmt.Sprintf("remove request %s -> %s", *from, *to))
+	}
+
+	return mutation.apply(plan, func() error {
+		return db.RemoveFriendRequest(ctx, *from, *to)
+	})
+}
+
+func parsePairFlags(fs *flag.FlagSet, args []string, a *string, b *string) error {
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	if *a == "" || *b == "" {
+		return errUsage
+	}
+
+	if *a == *b {
+		return errors.New("user ids must differ")
+	}
+
+	return nil
+}
+
+// planFriendship - describe edges that must change for users to become friends or strangers.
+func planFriendship(ctx context.Context, db mongo.DB, uid string, friendID string, add bool) ([]string, error) {
+	plan := []string{}
+
+	for _, pair := range [][2]string{{uid, friendID}, {friendID, uid}} {
+		friends, err := db.GetFriends(ctx, pair[0])
+		if err != nil {
+			return nil, fmt.Errorf("get friends of %s: %w", pair[0], err)
+		}
+
+		isFriend := slice.ContainsString(friends.FriendIDs, pair[1])
+
+		switch {
+		case add && !isFriend:
+			plan = append(plan, fmt.Sprintf("add friend %s to %s", pair[1], pair[0]))
+		case !add && isFriend:
+			plan = append(plan, fmt.Sprintf("remove friend %s from %s", pair[1], pair[0]))
+		}
+
+		if !add {
+			continue
+		}
+
+		requests, err := db.GetFriendRequests(ctx, pair[0])
+		if err != nil {
+			return nil, fmt.Errorf("get friend requests of %s: %w", pair[0], err)
+		}
+
+		if slice.ContainsString(requests.FriendIDs, pair[1]) {
+			plan = append(plan, fmt.Sprintf("remove request %s -> %s", pair[1], pair[0]))
+		}
+	}
+
+	return plan, nil
+}
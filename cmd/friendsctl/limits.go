@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+// limitFlag - optional non negative limit, nil when flag is not set.
+type limitFlag struct {
+	value *int
+}
+
+func (f *limitFlag) String() string {
+	if f.value == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*f.value)
+}
+
+func (f *limitFlag) Set(s string) error {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		return errors.New("must be non negative integer")
+	}
+
+	f.value = &v
+
+	return nil
+}
+
+// runLimitsShow - print user limits override.
+func runLimitsShow(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("limits show", flag.ContinueOnError)
+
+	uid := fs.String("uid", "", "user id")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	if *uid == "" {
+		return errors.New(`"uid": cannot be empty`)
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	override, err := db.GetLimitsOverride(ctx, *uid)
+	if err != nil {
+		return fmt.Errorf("get limits override: %w", err)
+	}
+
+	if override == nil {
+		fmt.Println("no override, server defaults apply")
+
+		return nil
+	}
+
+	fmt.Printf("max friends:\t%s\n", formatLimit(override.MaxFriends))
+	fmt.Printf("max incoming:\t%s\n", formatLimit(override.MaxIncomingPending))
+	fmt.Printf("max outgoing:\t%s\n", formatLimit(override.MaxOutgoingPending))
+	fmt.Printf("reason:\t%s\n", override.Reason)
+	fmt.Printf("updated at:\t%s\n", override.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
+
+	return nil
+}
+
+// runLimitsSet - override user limits, flags not given keep server defaults.
+func runLimitsSet(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("limits set", flag.ContinueOnError)
+
+	uid := fs.String("uid", "", "user id")
+	reason := fs.String("reason", "", "why user gets custom limits")
+
+	maxFriends := &limitFlag{}
+	maxIncoming := &limitFlag{}
+	maxOutgoing := &limitFlag{}
+
+	fs.Var(maxFriends, "max-friends", "max friends, 0 disables limit")
+	fs.Var(maxIncoming, "max-incoming", "max incoming pending requests, 0 disables limit")
+	fs.Var(maxOutgoing, "max-outgoing", "max outgoing pending requests, 0 disables limit")
+
+	mutation := addMutationFlags(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	if *uid == "" {
+		return errors.New(`"uid": cannot be empty`)
+	}
+
+	override := &mongo.LimitsOverride{
+		UID:                *uid,
+		MaxFriends:         maxFriends.value,
+		MaxIncomingPending: maxIncoming.value,
+		MaxOutgoingPending: maxOutgoing.value,
+		Reason:             *reason,
+	}
+
+	if override.IsEmpty() {
+		return errors.New("at least one of -max-friends, -max-incoming, -max-outgoing must be set")
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	plan := []string{
+		fmt.Sprintf("set %s limits: friends %s, incoming %s, outgoing %s", *uid,
+			formatLimit(override.MaxFriends), formatLimit(override.MaxIncomingPending), formatLimit(override.MaxOutgoingPending)),
+	}
+
+	return mutation.apply(plan, func() error {
+		return db.SetLimitsOverride(ctx, override)
+	})
+}
+
+// runLimitsReset - drop user limits override, server defaults apply again.
+func runLimitsReset(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("limits reset", flag.ContinueOnError)
+
+	uid := fs.String("uid", "", "user id")
+	mutation := addMutationFlags(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	if *uid == "" {
+		return errors.New(`"uid": cannot be empty`)
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	override, err := db.GetLimitsOverride(ctx, *uid)
+	if err != nil {
+		return fmt.Errorf("get limits override: %w", err)
+	}
+
+	plan := []string{}
+	if override != nil {
+		plan = append(plan, fmt.Sprintf("remove %s limits override", *uid))
+	}
+
+	return mutation.apply(plan, func() error {
+		return db.DeleteLimitsOverride(ctx, *uid)
+	})
+}
+
+func formatLimit(limit *int) string {
+	switch {
+	case limit == nil:
+		return "default"
+	case *limit == 0:
+		return "unlimited"
+	default:
+		return strconv.Itoa(*limit)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"os/user"
+	"syscall"
+
+	"github.com/daniilty/sharenote-friends/internal/audit"
+	"github.com/daniilty/sharenote-friends/internal/config"
+)
+
+const (
+	exitCodeUsageError = 1
+	exitCodeRunError   = 2
+)
+
+const usage = `friendsctl - sharenote friends operator tool.
+
+Usage:
+  friendsctl [config flags] command [flags]
+
+Config flags are read like server config: -config FILE or CONFIG_FILE env,
+then env and -key flags, e.g. -mongo.db_name. Server config keys not used by
+friendsctl are ignored, -h lists config flags, -print-config prints resolved config.
+
+Commands:
+  friendsctl friends show -uid ID [-names]         print friends, incoming and outgoing requests
+  friendsctl friends add -uid ID -friend ID        make users friends without request
+  friendsctl friends remove -uid ID -friend ID     remove friendship in both directions
+  friendsctl requests remove -from ID -to ID       drop pending friend request
+  friendsctl user purge -uid ID                    remove user friends and requests
+  friendsctl edges dump [-type friend|request]     print graph edges as JSON lines
+  friendsctl export -uid ID [-out FILE]            write user data export archive
+  friendsctl events replay [flags]                 reprocess users topic history
+  friendsctl auth token [flags]                    sign token with local test key
+  friendsctl migrate timestamps                    backfill missing friendship and request timestamps
+  friendsctl limits show -uid ID                   print user friends and pending requests limits override
+  friendsctl limits set -uid ID [flags]            override user limits, -max-friends, -max-incoming, -max-outgoing
+  friendsctl limits reset -uid ID                  drop user limits override
+  friendsctl counts recompute                      rebuild friends and pending requests counters
+
+Commands changing data accept -dry-run and ask for confirmation unless -yes is set.
+`
+
+type command func(ctx context.Context, cfg *ctlConfig, args []string) error
+
+var commands = map[string]map[string]command{
+	"friends": {
+		"show":   runFriendsShow,
+		"add":    runFriendsAdd,
+		"remove": runFriendsRemove,
+	},
+	"requests": {
+		"remove": runRequestsRemove,
+	},
+	"user": {
+		"purge": runUserPurge,
+	},
+	"edges": {
+		"dump": runEdgesDump,
+	},
+	"export": {
+		"": runExport,
+	},
+	"events": {
+		"replay": runEventsReplay,
+	},
+	"auth": {
+		"token": runAuthToken,
+	},
+	"migrate": {
+		"timestamps": runMigrateTimestamps,
+	},
+	"limits": {
+		"show":  runLimitsShow,
+		"set":   runLimitsSet,
+		"reset": runLimitsReset,
+	},
+	"counts": {
+		"recompute": runCountsRecompute,
+	},
+}
+
+func run(args []string) error {
+	cfg := &ctlConfig{}
+
+	opts, err := config.LoadPartial("friendsctl", cfg, args)
+	if err != nil {
+		if config.IsHelp(err) {
+			return errUsage
+		}
+
+		return err
+	}
+
+	if opts.PrintConfig {
+		return config.Print(os.Stdout, cfg)
+	}
+
+	args = opts.Args
+	if len(args) < 1 {
+		return errUsage
+	}
+
+	subcommands, ok := commands[args[0]]
+	if !ok {
+		return errUsage
+	}
+
+	// commands without subcommands are registered under empty name
+	cmd, ok := subcommands[""]
+	args = args[1:]
+
+	if !ok {
+		if len(args) < 1 {
+			return errUsage
+		}
+
+		cmd, ok = subcommands[args[0]]
+		if !ok {
+			return errUsage
+		}
+
+		args = args[1:]
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ctx = audit.WithActor(ctx, audit.Actor{
+		ID:     operatorID(),
+		Source: audit.SourceAdminCLI,
+	})
+
+	return cmd(ctx, cfg, args)
+}
+
+// operatorID - OS user running command, recorded in audit entries.
+func operatorID() string {
+	u, err := user.Current()
+	if err != nil {
+		return "unknown"
+	}
+
+	return u.Username
+}
+
+func main() {
+	err := run(os.Args[1:])
+	if err != nil {
+		if err == errUsage {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(exitCodeUsageError)
+		}
+
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(exitCodeRunError)
+	}
+}
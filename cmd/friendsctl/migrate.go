@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+// runMigrateTimestamps - backfill friendships and requests stored before timestamps were recorded
+// with unknown time sentinel.
+func runMigrateTimestamps(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("migrate timestamps", flag.ContinueOnError)
+
+	mutation := addMutationFlags(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	backfills, err := db.BackfillTimestamps(ctx, false)
+	if err != nil {
+		return fmt.Errorf("count missing timestamps: %w", err)
+	}
+
+	plan := []string{}
+
+	for _, b := range backfills {
+		if b.IDs > 0 {
+			plan = append(plan, fmt.Sprintf("%s: set unknown timestamp for %d ids in %d documents",
+				b.Collection, b.IDs, b.Documents))
+		}
+	}
+
+	return mutation.apply(plan, func() error {
+		_, err := db.BackfillTimestamps(ctx, true)
+
+		return err
+	})
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+func connectDB(ctx context.Context, cfg *mongo.Config) (*mongo.DBImpl, func(), error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mongoClient, err := mongo.Connect(ctx, cfg.ConnString)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db := mongoClient.Database(cfg.DBName)
+
+	disconnect := func() {
+		mongoClient.Disconnect(context.Background())
+	}
+
+	// operator changes are not bound by friends limits
+	return mongo.NewDBImpl(db, cfg.Collections, mongo.Limits{}), disconnect, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var errNotConfirmed = errors.New("aborted")
+
+// mutationFlags - flags shared by commands changing data.
+type mutationFlags struct {
+	dryRun *bool
+	yes    *bool
+}
+
+func addMutationFlags(fs *flag.FlagSet) *mutationFlags {
+	return &mutationFlags{
+		dryRun: fs.Bool("dry-run", false, "print planned changes without applying them"),
+		yes:    fs.Bool("yes", false, "skip confirmation prompt"),
+	}
+}
+
+// apply - print plan, ask for confirmation and run fn unless dry run is set.
+func (m *mutationFlags) apply(plan []string, fn func() error) error {
+	if len(plan) == 0 {
+		fmt.Println("nothing to change")
+
+		return nil
+	}
+
+	for i := range plan {
+		fmt.Println(plan[i])
+	}
+
+	if *m.dryRun {
+		fmt.Println("dry run, nothing changed")
+
+		return nil
+	}
+
+	if !*m.yes {
+		ok, err := confirm("apply changes?")
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return errNotConfirmed
+		}
+	}
+
+	err := fn()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("done")
+
+	return nil
+}
+
+func confirm(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("read confirmation: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// runUserPurge - remove every user reference like users delete event does.
+func runUserPurge(ctx context.Context, cfg *ctlConfig, args []string) error {
+	fs := flag.NewFlagSet("user purge", flag.ContinueOnError)
+
+	uid := fs.String("uid", "", "user id")
+	mutation := addMutationFlags(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return errUsage
+	}
+
+	if *uid == "" {
+		return errors.New(`"uid": cannot be empty`)
+	}
+
+	db, disconnect, err := connectDB(ctx, &cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	refs, err := db.GetUserReferences(ctx, *uid)
+	if err != nil {
+		return fmt.Errorf("get user references: %w", err)
+	}
+
+	plan := []string{}
+
+	if !refs.IsEmpty() {
+		plan = append(plan, fmt.Sprintf("purge %s: remove %d friends, %d friend of, %d incoming requests, %d outgoing requests, "+
+			"%d following, %d followers, %d blocks, %d invites, %d limits overrides, %d counters, %d settings",
+			*uid, refs.Friends, refs.FriendOf, refs.IncomingRequests, refs.OutgoingRequests,
+			refs.Following, refs.Followers, refs.Blocks, refs.Invites, refs.LimitsOverride, refs.Counts, refs.Settings))
+	}
+
+	return mutation.apply(plan, func() error {
+		return db.RemoveUser(ctx, *uid)
+	})
+}
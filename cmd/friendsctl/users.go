@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/daniilty/sharenote-friends/internal/config"
+	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+	"github.com/daniilty/sharenote-friends/internal/ratelimit"
+	schema "github.com/daniilty/sharenote-grpc-schema"
+	"google.golang.org/grpc"
+)
+
+// newService - core service resolving user names through users.grpc_addr.
+func newService(ctx context.Context, cfg *ctlConfig, db mongo.DB) (core.Service, func(), error) {
+	err := config.Require(cfg, "users.grpc_addr")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := grpc.DialContext(ctx, cfg.Users.GRPCAddr, grpc.WithInsecure())
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial users service: %w", err)
+	}
+
+	closeConn := func() {
+		conn.Close()
+	}
+
+	return core.NewService(db, schema.NewUsersClient(conn), ratelimit.Unlimited{}, core.AntiSpamConfig{}, core.SearchConfig{}, core.InviteConfig{}, core.PathConfig{}), closeConn, nil
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/auth"
+	"github.com/daniilty/sharenote-friends/internal/config"
+	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/daniilty/sharenote-friends/internal/kafka"
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+	"github.com/daniilty/sharenote-friends/internal/ratelimit"
+	"github.com/daniilty/sharenote-friends/internal/server"
+	"github.com/daniilty/sharenote-friends/internal/usersclient"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	minInviteSecretLen = 32
+	minAuditTokenLen   = 32
+)
+
+// serverConfig - server config, see config.Load for layering and tags.
+type serverConfig struct {
+	HTTP struct {
+		Addr           string        `config:"http.addr" env:"HTTP_SERVER_ADDR" required:"true" usage:"public API listen address"`
+		ReadTimeout    time.Duration `config:"http.read_timeout" env:"HTTP_READ_TIMEOUT" default:"10s" usage:"time to read whole request"`
+		WriteTimeout   time.Duration `config:"http.write_timeout" env:"HTTP_WRITE_TIMEOUT" default:"5m30s" usage:"time to write response, covers export"`
+		IdleTimeout    time.Duration `config:"http.idle_timeout" env:"HTTP_IDLE_TIMEOUT" default:"2m" usage:"keep-alive idle time"`
+		RequestTimeout time.Duration `config:"http.request_timeout" env:"HTTP_REQUEST_TIMEOUT" default:"15s" usage:"route handler timeout"`
+		ExportTimeout  time.Duration `config:"http.export_timeout" env:"HTTP_EXPORT_TIMEOUT" default:"5m" usage:"export handler timeout"`
+		MaxBodyBytes   int64         `config:"http.max_body_bytes" env:"HTTP_MAX_BODY_BYTES" default:"1048576" usage:"request body limit"`
+	}
+	Admin struct {
+		Addr       string `config:"admin.addr" env:"ADMIN_HTTP_SERVER_ADDR" default:":9090" usage:"metrics listen address"`
+		AuditToken string `config:"admin.audit_token" env:"ADMIN_AUDIT_TOKEN" secret:"true" usage:"bearer token for audit route, empty disables it"`
+	}
+	Tracing struct {
+		OTLPEndpoint string `config:"tracing.otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" usage:"OTLP/gRPC collector url, e.g. http://otel-collector:4317, empty disables tracing"`
+		ServiceName  string `config:"tracing.service_name" env:"OTEL_SERVICE_NAME" default:"sharenote-friends"`
+	}
+	Auth struct {
+		Mode                string        `config:"auth.mode" env:"AUTH_MODE" default:"header" usage:"header or jwks"`
+		JWKSFile            string        `config:"auth.jwks_file" env:"AUTH_JWKS_FILE"`
+		JWKSURL             string        `config:"auth.jwks_url" env:"AUTH_JWKS_URL"`
+		JWKSRefreshInterval time.Duration `config:"auth.jwks_refresh_interval" env:"AUTH_JWKS_REFRESH_INTERVAL" default:"15m"`
+		Audience            string        `config:"auth.audience" env:"AUTH_AUDIENCE" usage:"required in jwks mode"`
+		Issuer              string        `config:"auth.issuer" env:"AUTH_ISSUER" usage:"required in jwks mode"`
+		Leeway              time.Duration `config:"auth.leeway" env:"AUTH_LEEWAY" default:"30s" usage:"allowed clock skew"`
+	}
+	Users struct {
+		GRPCAddr           string        `config:"users.grpc_addr" env:"USERS_GRPC_ADDR" required:"true" usage:"users service address"`
+		Timeout            time.Duration `config:"users.timeout" env:"USERS_GRPC_TIMEOUT" default:"2s" usage:"per attempt deadline"`
+		MaxRetries         int           `config:"users.max_retries" env:"USERS_GRPC_MAX_RETRIES" default:"2"`
+		BaseBackoff        time.Duration `config:"users.base_backoff" env:"USERS_GRPC_BASE_BACKOFF" default:"50ms"`
+		MaxBackoff         time.Duration `config:"users.max_backoff" env:"USERS_GRPC_MAX_BACKOFF" default:"1s"`
+		BreakerThreshold   int           `config:"users.breaker_threshold" env:"USERS_GRPC_BREAKER_THRESHOLD" default:"5"`
+		BreakerOpenTimeout time.Duration `config:"users.breaker_open_timeout" env:"USERS_GRPC_BREAKER_OPEN_TIMEOUT" default:"10s"`
+		BatchSize          int           `config:"users.batch_size" env:"USERS_GRPC_BATCH_SIZE" default:"100"`
+		BatchConcurrency   int           `config:"users.batch_concurrency" env:"USERS_GRPC_BATCH_CONCURRENCY" default:"4"`
+		Cache              struct {
+			Backend string        `config:"users.cache.backend" env:"USERS_CACHE_BACKEND" default:"memory" usage:"none, memory or redis"`
+			Size    int           `config:"users.cache.size" env:"USERS_CACHE_SIZE" default:"10000"`
+			TTL     time.Duration `config:"users.cache.ttl" env:"USERS_CACHE_TTL" default:"5m"`
+		}
+	}
+	Redis struct {
+		Addr     string `config:"redis.addr" env:"REDIS_ADDR" usage:"required by redis backends"`
+		Password string `config:"redis.password" env:"REDIS_PASSWORD" secret:"true"`
+		DB       int    `config:"redis.db" env:"REDIS_DB" default:"0"`
+		PoolSize int    `config:"redis.pool_size" env:"REDIS_POOL_SIZE" default:"10"`
+	}
+	Mongo  mongo.Config
+	Kafka  kafka.ConsumerConfig
+	Events struct {
+		// TIMEOUT is the legacy name, plain integers are seconds
+		Timeout time.Duration `config:"events.timeout" env:"EVENTS_TIMEOUT,TIMEOUT" default:"10s" usage:"users event handling timeout"`
+	}
+	Shutdown struct {
+		DrainDelay time.Duration `config:"shutdown.drain_delay" env:"SHUTDOWN_DRAIN_DELAY" default:"5s" usage:"not ready period before shutdown"`
+	}
+	FriendRequests struct {
+		RateLimitBackend string `config:"friend_requests.rate_limit_backend" env:"FRIEND_REQUESTS_RATE_LIMIT_BACKEND" default:"memory" usage:"memory or redis"`
+		PerMinute        int64  `config:"friend_requests.per_minute" env:"FRIEND_REQUESTS_PER_MINUTE" default:"10"`
+		PerDay           int64  `config:"friend_requests.per_day" env:"FRIEND_REQUESTS_PER_DAY" default:"200"`
+	}
+	Limits struct {
+		MaxFriends         int `config:"limits.max_friends" env:"FRIENDS_MAX_FRIENDS" default:"5000" usage:"0 disables limit, per user overrides are set with friendsctl"`
+		MaxIncomingPending int `config:"limits.max_incoming_pending" env:"FRIENDS_MAX_INCOMING_PENDING" default:"1000" usage:"0 disables limit"`
+		MaxOutgoingPending int `config:"limits.max_outgoing_pending" env:"FRIENDS_MAX_OUTGOING_PENDING" default:"1000" usage:"unanswered requests sent by user, 0 disables limit"`
+	}
+	Search struct {
+		CacheSize int           `config:"search.cache_size" env:"FRIENDS_SEARCH_CACHE_SIZE" default:"10000" usage:"users with cached resolved friends, 0 disables cache"`
+		CacheTTL  time.Duration `config:"search.cache_ttl" env:"FRIENDS_SEARCH_CACHE_TTL" default:"30s"`
+	}
+	Path struct {
+		MaxDepth   int `config:"path.max_depth" env:"FRIENDS_PATH_MAX_DEPTH" default:"4" usage:"max friendships in shortest path between users"`
+		MaxVisited int `config:"path.max_visited" env:"FRIENDS_PATH_MAX_VISITED" default:"20000" usage:"users visited by one path search before it gives up"`
+	}
+	Invites struct {
+		Secret    string        `config:"invites.secret" env:"INVITES_SECRET" required:"true" secret:"true" usage:"invite tokens signing key, at least 32 bytes"`
+		TTL       time.Duration `config:"invites.ttl" env:"INVITES_TTL" default:"168h" usage:"default invite lifetime"`
+		MaxTTL    time.Duration `config:"invites.max_ttl" env:"INVITES_MAX_TTL" default:"720h"`
+		MaxActive int           `config:"invites.max_active" env:"INVITES_MAX_ACTIVE" default:"20" usage:"active invites per user, 0 disables limit"`
+	}
+	Abuse struct {
+		DeclineRatioThreshold float64 `config:"abuse.decline_ratio_threshold" env:"ABUSE_DECLINE_RATIO_THRESHOLD" default:"0.8"`
+		DeclineRatioMinSample int64   `config:"abuse.decline_ratio_min_sample" env:"ABUSE_DECLINE_RATIO_MIN_SAMPLE" default:"20"`
+	}
+}
+
+// loadConfig - load and validate server config from defaults, file, env and flags.
+func loadConfig(args []string) (*serverConfig, config.Options, error) {
+	cfg := &serverConfig{}
+
+	opts, err := config.Load("server", cfg, args)
+	if err != nil {
+		if config.IsHelp(err) {
+			return nil, opts, err
+		}
+
+		errs := config.Errors{}
+		errs.Add(err)
+		errs.Add(cfg.validate())
+
+		return nil, opts, errs
+	}
+
+	err = cfg.validate()
+	if err != nil {
+		return nil, opts, err
+	}
+
+	return cfg, opts, nil
+}
+
+// validate - check values and dependencies between them, reports every problem.
+func (c *serverConfig) validate() error {
+	errs := config.Errors{}
+
+	if c.HTTP.RequestTimeout >= c.HTTP.WriteTimeout {
+		errs.Add(errors.New("http.request_timeout must be less than http.write_timeout"))
+	}
+
+	if c.HTTP.ExportTimeout >= c.HTTP.WriteTimeout {
+		errs.Add(errors.New("http.export_timeout must be less than http.write_timeout"))
+	}
+
+	errs.Add(c.authConfig().Validate())
+	errs.Add(c.usersClientConfig().Validate())
+	errs.Add(c.usersCacheConfig().Validate())
+	errs.Add(c.rateLimitConfig().Validate())
+
+	errs.Add(c.Mongo.Validate())
+	errs.Add(c.Kafka.Validate())
+
+	if c.usesRedis() && c.Redis.Addr == "" {
+		errs.Add(errors.New("redis.addr: required by redis users cache or rate limit backend"))
+	}
+
+	if c.Abuse.DeclineRatioThreshold <= 0 || c.Abuse.DeclineRatioThreshold > 1 {
+		errs.Add(fmt.Errorf("abuse.decline_ratio_threshold must be in (0, 1], got %v", c.Abuse.DeclineRatioThreshold))
+	}
+
+	if c.Admin.AuditToken != "" && len(c.Admin.AuditToken) < minAuditTokenLen {
+		errs.Add(fmt.Errorf("admin.audit_token must be at least %d bytes", minAuditTokenLen))
+	}
+
+	if len(c.Invites.Secret) < minInviteSecretLen {
+		errs.Add(fmt.Errorf("invites.secret must be at least %d bytes", minInviteSecretLen))
+	}
+
+	if c.Invites.TTL <= 0 || c.Invites.TTL > c.Invites.MaxTTL {
+		errs.Add(errors.New("invites.ttl must be positive and not greater than invites.max_ttl"))
+	}
+
+	if c.Path.MaxDepth <= 0 || c.Path.MaxVisited <= 0 {
+		errs.Add(errors.New("path.max_depth and path.max_visited must be positive"))
+	}
+
+	if c.Events.Timeout <= 0 {
+		errs.Add(errors.New("events.timeout must be positive"))
+	}
+
+	return errs.Err()
+}
+
+func (c *serverConfig) usesRedis() bool {
+	return c.Users.Cache.Backend == usersclient.CacheBackendRedis || c.FriendRequests.RateLimitBackend == ratelimit.BackendRedis
+}
+
+func (c *serverConfig) httpConfig() server.HTTPConfig {
+	return server.HTTPConfig{
+		Addr:           c.HTTP.Addr,
+		ReadTimeout:    c.HTTP.ReadTimeout,
+		WriteTimeout:   c.HTTP.WriteTimeout,
+		IdleTimeout:    c.HTTP.IdleTimeout,
+		RequestTimeout: c.HTTP.RequestTimeout,
+		ExportTimeout:  c.HTTP.ExportTimeout,
+		MaxBodyBytes:   c.HTTP.MaxBodyBytes,
+	}
+}
+
+func (c *serverConfig) authConfig() *auth.Config {
+	return &auth.Config{
+		Mode:            c.Auth.Mode,
+		JWKSFile:        c.Auth.JWKSFile,
+		JWKSURL:         c.Auth.JWKSURL,
+		RefreshInterval: c.Auth.JWKSRefreshInterval,
+		Audience:        c.Auth.Audience,
+		Issuer:          c.Auth.Issuer,
+		Leeway:          c.Auth.Leeway,
+	}
+}
+
+func (c *serverConfig) usersClientConfig() *usersclient.Config {
+	return &usersclient.Config{
+		Timeout:            c.Users.Timeout,
+		MaxRetries:         c.Users.MaxRetries,
+		BaseBackoff:        c.Users.BaseBackoff,
+		MaxBackoff:         c.Users.MaxBackoff,
+		BreakerThreshold:   c.Users.BreakerThreshold,
+		BreakerOpenTimeout: c.Users.BreakerOpenTimeout,
+		BatchSize:          c.Users.BatchSize,
+		BatchConcurrency:   c.Users.BatchConcurrency,
+	}
+}
+
+func (c *serverConfig) usersCacheConfig() *usersclient.CacheConfig {
+	return &usersclient.CacheConfig{
+		Backend: c.Users.Cache.Backend,
+		Size:    c.Users.Cache.Size,
+		TTL:     c.Users.Cache.TTL,
+	}
+}
+
+func (c *serverConfig) redisOptions() *redis.Options {
+	return &redis.Options{
+		Addr:     c.Redis.Addr,
+		Password: c.Redis.Password,
+		DB:       c.Redis.DB,
+		PoolSize: c.Redis.PoolSize,
+	}
+}
+
+func (c *serverConfig) rateLimitConfig() *ratelimit.Config {
+	return &ratelimit.Config{
+		Backend:   c.FriendRequests.RateLimitBackend,
+		PerMinute: c.FriendRequests.PerMinute,
+		PerDay:    c.FriendRequests.PerDay,
+	}
+}
+
+func (c *serverConfig) antiSpamConfig() core.AntiSpamConfig {
+	return core.AntiSpamConfig{
+		DeclineRatioThreshold: c.Abuse.DeclineRatioThreshold,
+		DeclineRatioMinSample: c.Abuse.DeclineRatioMinSample,
+	}
+}
+
+func (c *serverConfig) searchConfig() core.SearchConfig {
+	return core.SearchConfig{
+		CacheSize: c.Search.CacheSize,
+		CacheTTL:  c.Search.CacheTTL,
+	}
+}
+
+func (c *serverConfig) pathConfig() core.PathConfig {
+	return core.PathConfig{
+		MaxDepth:   c.Path.MaxDepth,
+		MaxVisited: c.Path.MaxVisited,
+	}
+}
+
+func (c *serverConfig) inviteConfig() core.InviteConfig {
+	return core.InviteConfig{
+		Secret:    []byte(c.Invites.Secret),
+		TTL:       c.Invites.TTL,
+		MaxTTL:    c.Invites.MaxTTL,
+		MaxActive: c.Invites.MaxActive,
+	}
+}
+
+func (c *serverConfig) friendLimits() mongo.Limits {
+	return mongo.Limits{
+		MaxFriends:         c.Limits.MaxFriends,
+		MaxIncomingPending: c.Limits.MaxIncomingPending,
+		MaxOutgoingPending: c.Limits.MaxOutgoingPending,
+	}
+}
+
+func (c *serverConfig) adminConfig() server.AdminConfig {
+	return server.AdminConfig{
+		Addr:       c.Admin.Addr,
+		AuditToken: c.Admin.AuditToken,
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,50 +10,86 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/daniilty/sharenote-friends/internal/auth"
+	"github.com/daniilty/sharenote-friends/internal/config"
 	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/daniilty/sharenote-friends/internal/health"
 	"github.com/daniilty/sharenote-friends/internal/kafka"
 	"github.com/daniilty/sharenote-friends/internal/mongo"
+	"github.com/daniilty/sharenote-friends/internal/ratelimit"
 	"github.com/daniilty/sharenote-friends/internal/server"
+	"github.com/daniilty/sharenote-friends/internal/tracing"
 	"github.com/daniilty/sharenote-friends/internal/users"
+	"github.com/daniilty/sharenote-friends/internal/usersclient"
 	schema "github.com/daniilty/sharenote-grpc-schema"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
 
 const (
 	exitCodeInitError = 2
+
+	tracesFlushTimeout = 5 * time.Second
 )
 
+var errConsumerNotListening = errors.New("users events consumer is not listening")
+
 func run() error {
-	cfg, err := loadEnvConfig()
+	cfg, opts, err := loadConfig(os.Args[1:])
 	if err != nil {
 		return err
 	}
 
+	if opts.PrintConfig {
+		return config.Print(os.Stdout, cfg)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
-	conn, err := grpc.DialContext(ctx, cfg.usersGRPCAddr, grpc.WithInsecure())
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		ServiceName:  cfg.Tracing.ServiceName,
+	})
 	if err != nil {
 		cancel()
 
 		return err
 	}
 
-	mongoClient, err := mongo.Connect(context.Background(), cfg.mongoConnString)
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), tracesFlushTimeout)
+		defer shutdownCancel()
+
+		shutdownTracing(shutdownCtx)
+	}()
+
+	conn, err := grpc.DialContext(ctx, cfg.Users.GRPCAddr,
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	)
 	if err != nil {
 		cancel()
 
 		return err
 	}
 
-	db := mongoClient.Database(cfg.mongoDBName)
-	friendsCollection := db.Collection(cfg.mongoFriendsCollectionName)
-	friendRequestsCollection := db.Collection(cfg.mongoFriendRequestsCollectionName)
+	mongoClient, err := mongo.Connect(context.Background(), cfg.Mongo.ConnString)
+	if err != nil {
+		cancel()
 
-	d := mongo.NewDBImpl(db, friendRequestsCollection, friendsCollection)
+		return err
+	}
 
-	client := schema.NewUsersClient(conn)
-	service := core.NewService(d, client)
+	d := mongo.NewDBImpl(mongoClient.Database(cfg.Mongo.DBName), cfg.Mongo.Collections, cfg.friendLimits())
+
+	err = d.EnsureIndexes(ctx)
+	if err != nil {
+		cancel()
+
+		return err
+	}
 
 	loggerCfg := zap.NewProductionConfig()
 
@@ -63,11 +100,69 @@ func run() error {
 		return err
 	}
 
-	httpServer := server.NewHTTP(cfg.httpAddr, logger.Sugar(), service)
+	var client schema.UsersClient = usersclient.NewBatchedClient(
+		usersclient.NewResilientClient(schema.NewUsersClient(conn), *cfg.usersClientConfig()),
+		logger.Sugar(),
+		cfg.Users.BatchSize,
+		cfg.Users.BatchConcurrency,
+	)
+
+	var redisClient *redis.Client
+
+	if cfg.usesRedis() {
+		redisClient = redis.NewClient(cfg.redisOptions())
+		defer redisClient.Close()
+	}
+
+	var usersCache usersclient.Invalidator
+
+	switch cfg.Users.Cache.Backend {
+	case usersclient.CacheBackendMemory:
+		cachedClient := usersclient.NewCachedClient(client, usersclient.NewLRUCache(cfg.Users.Cache.Size, cfg.Users.Cache.TTL))
+		client, usersCache = cachedClient, cachedClient
+	case usersclient.CacheBackendRedis:
+		cachedClient := usersclient.NewCachedClient(client, usersclient.NewRedisCache(redisClient, cfg.Users.Cache.TTL))
+		client, usersCache = cachedClient, cachedClient
+	}
+
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.FriendRequests.RateLimitBackend == ratelimit.BackendRedis {
+		rateLimitStore = ratelimit.NewRedisStore(redisClient)
+	}
 
-	consumer := kafka.NewConsumerImpl(cfg.kafkaTopic, []string{cfg.kafkaBroker}, cfg.kafkaGroupID)
+	requestsLimiter := ratelimit.NewWindowLimiter(rateLimitStore, "friend_requests", cfg.rateLimitConfig().Windows()...)
 
-	usersHandler := users.NewEventsHandler(logger.Sugar(), time.Duration(cfg.eventsTimeout)*time.Second, d, consumer)
+	service := core.NewService(d, client, requestsLimiter, cfg.antiSpamConfig(), cfg.searchConfig(), cfg.inviteConfig(), cfg.pathConfig())
+
+	consumer, err := kafka.NewConsumerImpl(cfg.Kafka.Topic, cfg.Kafka.GroupID, &cfg.Kafka.Config)
+	if err != nil {
+		cancel()
+
+		return err
+	}
+
+	usersHandler := users.NewEventsHandler(logger.Sugar(), cfg.Events.Timeout, d, consumer, usersCache)
+
+	readiness := health.NewReadiness(cfg.Shutdown.DrainDelay)
+	readiness.Add("mongo", health.CheckerFunc(d.Ping))
+	readiness.Add("users_grpc", health.GRPCConnChecker(conn))
+	readiness.Add("kafka", health.CheckerFunc(func(ctx context.Context) error {
+		if !usersHandler.Listening() {
+			return errConsumerNotListening
+		}
+
+		return consumer.Ping(ctx)
+	}))
+
+	authenticator, err := newAuthenticator(ctx, cfg.authConfig())
+	if err != nil {
+		cancel()
+
+		return err
+	}
+
+	httpServer := server.NewHTTP(cfg.httpConfig(), logger.Sugar(), service, authenticator, readiness)
+	adminServer := server.NewAdmin(cfg.adminConfig(), logger.Sugar(), service)
 
 	wg := &sync.WaitGroup{}
 
@@ -77,6 +172,12 @@ func run() error {
 		wg.Done()
 	}()
 
+	wg.Add(1)
+	go func() {
+		adminServer.Run(ctx)
+		wg.Done()
+	}()
+
 	wg.Add(1)
 	go func() {
 		usersHandler.Listen(ctx)
@@ -93,9 +194,36 @@ func run() error {
 	return nil
 }
 
+func newAuthenticator(ctx context.Context, cfg *auth.Config) (auth.Authenticator, error) {
+	if cfg.Mode == auth.ModeHeader {
+		return auth.HeaderAuthenticator{}, nil
+	}
+
+	var (
+		keySet auth.KeySet
+		err    error
+	)
+
+	if cfg.JWKSFile != "" {
+		keySet, err = auth.NewFileKeySet(cfg.JWKSFile, cfg.RefreshInterval)
+	} else {
+		keySet, err = auth.NewURLKeySet(ctx, cfg.JWKSURL, cfg.RefreshInterval)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewJWTAuthenticator(keySet, cfg), nil
+}
+
 func main() {
 	err := run()
 	if err != nil {
+		if config.IsHelp(err) {
+			return
+		}
+
 		fmt.Fprint(os.Stderr, err.Error())
 		os.Exit(exitCodeInitError)
 	}
@@ -0,0 +1,54 @@
+package audit
+
+import "context"
+
+const (
+	// SourceHTTP - change requested through public API.
+	SourceHTTP = "http"
+	// SourceKafka - change caused by users event.
+	SourceKafka = "kafka"
+	// SourceAdminCLI - change made by operator with friendsctl.
+	SourceAdminCLI = "admin_cli"
+	// SourceUnknown - actor was not put into context.
+	SourceUnknown = "unknown"
+)
+
+const (
+	ActionFriendRequested       = "friend_requested"
+	ActionFriendRequestDeclined = "friend_request_declined"
+	ActionFriendRequestRemoved  = "friend_request_removed"
+	ActionFriendAdded           = "friend_added"
+	ActionFriendRemoved         = "friend_removed"
+	ActionFriendForceAdded      = "friend_force_added"
+	ActionFriendForceRemoved    = "friend_force_removed"
+	ActionFriendInviteRedeemed  = "friend_invite_redeemed"
+	ActionFollowed              = "followed"
+	ActionUnfollowed            = "unfollowed"
+	ActionFollowerRemoved       = "follower_removed"
+	ActionBlocked               = "blocked"
+	ActionUnblocked             = "unblocked"
+	ActionUserRemoved           = "user_removed"
+)
+
+type actorKey struct{}
+
+// Actor - who made the change and through what.
+type Actor struct {
+	ID     string
+	Source string
+}
+
+// WithActor - put change actor into context.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext - get change actor, ok is false when none was set.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	if !ok {
+		return Actor{Source: SourceUnknown}, false
+	}
+
+	return actor, true
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/daniilty/sharenote-auth/claims"
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+const authorizationHeader = "Authorization"
+
+// DefaultAlgorithm - signature algorithm of keys published without "alg",
+// token header never chooses algorithm.
+const DefaultAlgorithm = jwa.RS256
+
+var (
+	_ Authenticator = HeaderAuthenticator{}
+	_ Authenticator = (*JWTAuthenticator)(nil)
+)
+
+// ErrUnauthenticated - request has no valid credentials.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator - resolve request subject.
+type Authenticator interface {
+	// Authenticate - get request subject, errors wrap ErrUnauthenticated
+	// unless credentials could not be checked at all.
+	Authenticate(*http.Request) (*claims.Subject, error)
+}
+
+// HeaderAuthenticator - trusts bearer token claims verified by gateway.
+type HeaderAuthenticator struct{}
+
+func (HeaderAuthenticator) Authenticate(r *http.Request) (*claims.Subject, error) {
+	sub, err := claims.ParseHTTPHeader(r.Header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, err)
+	}
+
+	return sub, nil
+}
+
+// JWTAuthenticator - verifies bearer token signature, expiry, audience and issuer.
+type JWTAuthenticator struct {
+	keySet  KeySet
+	options []jwt.ParseOption
+
+	mu sync.Mutex
+	// source, pinned - last key set and its copy with algorithm set on every key.
+	source jwk.Set
+	pinned jwk.Set
+}
+
+// NewJWTAuthenticator - JWTAuthenticator constructor.
+func NewJWTAuthenticator(keySet KeySet, cfg *Config) *JWTAuthenticator {
+	options := []jwt.ParseOption{
+		// tokens issued without kid are accepted while key set has a single key
+		jwt.UseDefaultKey(true),
+		jwt.WithValidate(true),
+		jwt.WithRequiredClaim(jwt.ExpirationKey),
+		jwt.WithAcceptableSkew(cfg.Leeway),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithIssuer(cfg.Issuer),
+	}
+
+	return &JWTAuthenticator{
+		keySet:  keySet,
+		options: options,
+	}
+}
+
+func (j *JWTAuthenticator) Authenticate(r *http.Request) (*claims.Subject, error) {
+	return j.authenticate(r.Context(), r.Header)
+}
+
+func (j *JWTAuthenticator) authenticate(ctx context.Context, h http.Header) (*claims.Subject, error) {
+	set, err := j.keySet.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get key set: %w", err)
+	}
+
+	set, err = j.pinAlgorithms(set)
+	if err != nil {
+		return nil, fmt.Errorf("pin key algorithms: %w", err)
+	}
+
+	options := append([]jwt.ParseOption{jwt.WithKeySet(set)}, j.options...)
+
+	token, err := jwt.ParseHeader(h, authorizationHeader, options...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, err)
+	}
+
+	sub, err := claims.GetTokenSubject(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, err)
+	}
+
+	return sub, nil
+}
+
+// pinAlgorithms - key set where keys without "alg" verify DefaultAlgorithm only,
+// result is reused until key set changes.
+func (j *JWTAuthenticator) pinAlgorithms(set jwk.Set) (jwk.Set, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.source == set {
+		return j.pinned, nil
+	}
+
+	pinned := jwk.NewSet()
+
+	for i := 0; i < set.Len(); i++ {
+		key, _ := set.Get(i)
+
+		if key.Algorithm() == "" {
+			clone, err := key.Clone()
+			if err != nil {
+				return nil, err
+			}
+
+			err = clone.Set(jwk.AlgorithmKey, DefaultAlgorithm)
+			if err != nil {
+				return nil, err
+			}
+
+			key = clone
+		}
+
+		pinned.Add(key)
+	}
+
+	j.source = set
+	j.pinned = pinned
+
+	return pinned, nil
+}
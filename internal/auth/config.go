@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// ModeHeader - trust token claims, signature is checked by gateway.
+	ModeHeader = "header"
+	// ModeJWKS - verify token against JWKS.
+	ModeJWKS = "jwks"
+)
+
+// Config - authentication config.
+type Config struct {
+	Mode string
+	// JWKSFile - local JWKS path, mutually exclusive with JWKSURL.
+	JWKSFile string
+	JWKSURL  string
+	// RefreshInterval - how often key set is reloaded to pick up rotated keys.
+	RefreshInterval time.Duration
+	Audience        string
+	Issuer          string
+	// Leeway - allowed clock skew for exp, nbf and iat checks.
+	Leeway time.Duration
+}
+
+// Validate - check config consistency.
+func (c *Config) Validate() error {
+	switch c.Mode {
+	case ModeHeader:
+		return nil
+	case ModeJWKS:
+	default:
+		return fmt.Errorf("auth: unsupported mode %q", c.Mode)
+	}
+
+	if (c.JWKSFile == "") == (c.JWKSURL == "") {
+		return errors.New("auth: exactly one of jwks file and jwks url must be provided")
+	}
+
+	if c.RefreshInterval <= 0 {
+		return errors.New("auth: jwks refresh interval must be positive")
+	}
+
+	if c.Audience == "" || c.Issuer == "" {
+		return errors.New("auth: audience and issuer are required in jwks mode")
+	}
+
+	if c.Leeway < 0 {
+		return errors.New("auth: leeway cannot be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwk"
+)
+
+var (
+	_ KeySet = (*FileKeySet)(nil)
+	_ KeySet = (*URLKeySet)(nil)
+)
+
+// KeySet - source of token verification keys.
+type KeySet interface {
+	// Get - get current key set.
+	Get(context.Context) (jwk.Set, error)
+}
+
+// FileKeySet - JWKS file, reloaded when modified.
+type FileKeySet struct {
+	path            string
+	refreshInterval time.Duration
+
+	mu        sync.Mutex
+	set       jwk.Set
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewFileKeySet - FileKeySet constructor, fails when file is not a valid JWKS.
+func NewFileKeySet(path string, refreshInterval time.Duration) (*FileKeySet, error) {
+	f := &FileKeySet{
+		path:            path,
+		refreshInterval: refreshInterval,
+	}
+
+	err := f.reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Get - get key set, file is checked for changes at most once per refresh interval.
+// Previous key set is kept when reload fails.
+func (f *FileKeySet) Get(ctx context.Context) (jwk.Set, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if time.Since(f.checkedAt) >= f.refreshInterval {
+		_ = f.reload()
+	}
+
+	return f.set, nil
+}
+
+func (f *FileKeySet) reload() error {
+	f.checkedAt = time.Now()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return fmt.Errorf("auth: stat jwks file: %w", err)
+	}
+
+	if f.set != nil && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	bb, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("auth: read jwks file: %w", err)
+	}
+
+	set, err := jwk.Parse(bb)
+	if err != nil {
+		return fmt.Errorf("auth: parse jwks file: %w", err)
+	}
+
+	f.set = set
+	f.modTime = info.ModTime()
+
+	return nil
+}
+
+// URLKeySet - remote JWKS refreshed in background.
+type URLKeySet struct {
+	url     string
+	refresh *jwk.AutoRefresh
+}
+
+// NewURLKeySet - URLKeySet constructor, refreshing stops when ctx is done.
+// Longer Cache-Control max-age or Expires of JWKS response take precedence over refresh interval.
+func NewURLKeySet(ctx context.Context, url string, refreshInterval time.Duration) (*URLKeySet, error) {
+	refresh := jwk.NewAutoRefresh(ctx)
+	refresh.Configure(url, jwk.WithMinRefreshInterval(refreshInterval))
+
+	_, err := refresh.Refresh(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("auth: fetch jwks: %w", err)
+	}
+
+	return &URLKeySet{
+		url:     url,
+		refresh: refresh,
+	}, nil
+}
+
+// Get - get cached key set.
+func (u *URLKeySet) Get(ctx context.Context) (jwk.Set, error) {
+	return u.refresh.Fetch(ctx, u.url)
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	fileFlag        = "config"
+	fileEnv         = "CONFIG_FILE"
+	printConfigFlag = "print-config"
+	redacted        = "******"
+)
+
+// Options - loader options parsed from command line.
+type Options struct {
+	// File - config file path from -config flag or CONFIG_FILE env.
+	File string
+	// PrintConfig - print resolved config and exit.
+	PrintConfig bool
+	// Args - arguments left after flags, e.g. command name.
+	Args []string
+}
+
+// Load - fill tagged fields of dst, later layers override earlier ones:
+// defaults, YAML/TOML file, env, flags. Every problem is reported at once as Errors.
+func Load(name string, dst interface{}, args []string) (Options, error) {
+	return load(name, dst, args, false)
+}
+
+// LoadPartial - Load for tools reading part of service config,
+// file keys not described by dst are ignored.
+func LoadPartial(name string, dst interface{}, args []string) (Options, error) {
+	return load(name, dst, args, true)
+}
+
+func load(name string, dst interface{}, args []string, ignoreUnknown bool) (Options, error) {
+	opts := Options{}
+
+	fields, err := collectFields(dst)
+	if err != nil {
+		return opts, err
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.File, fileFlag, os.Getenv(fileEnv), "YAML or TOML config file, also "+fileEnv+" env")
+	fs.BoolVar(&opts.PrintConfig, printConfigFlag, false, "print resolved config with secrets redacted and exit")
+
+	flagValues := map[string]*string{}
+
+	for _, f := range fields {
+		flagValues[f.key] = fs.String(f.key, "", f.flagUsage())
+	}
+
+	err = fs.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Args = fs.Args()
+
+	errs := Errors{}
+
+	for _, f := range fields {
+		if f.def == "" {
+			continue
+		}
+
+		err = f.parse(f.def)
+		if err != nil {
+			return opts, fmt.Errorf("config: %s: bad default: %w", f.key, err)
+		}
+
+		f.set = false
+	}
+
+	if opts.File != "" {
+		errs.Add(applyFile(fields, opts.File, ignoreUnknown))
+	}
+
+	for _, f := range fields {
+		for _, name := range f.envs {
+			val, ok := os.LookupEnv(name)
+			if !ok {
+				continue
+			}
+
+			err = f.parse(val)
+			if err != nil {
+				errs.Add(fmt.Errorf("env %s: %w", name, err))
+			}
+
+			break
+		}
+	}
+
+	fs.Visit(func(fl *flag.Flag) {
+		val, ok := flagValues[fl.Name]
+		if !ok {
+			return
+		}
+
+		err := fieldByKey(fields, fl.Name).parse(*val)
+		if err != nil {
+			errs.Add(fmt.Errorf("flag -%s: %w", fl.Name, err))
+		}
+	})
+
+	for _, f := range fields {
+		if f.required && !f.set && f.isZero() {
+			errs.Add(f.requiredError())
+		}
+	}
+
+	return opts, errs.Err()
+}
+
+func applyFile(fields []*field, path string, ignoreUnknown bool) error {
+	values, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	errs := Errors{}
+
+	for _, key := range sortedKeys(values) {
+		f := fieldByKey(fields, key)
+		if f == nil {
+			if ignoreUnknown {
+				continue
+			}
+
+			errs.Add(fmt.Errorf("config file %s: unknown key %q", path, key))
+
+			continue
+		}
+
+		err = f.parse(values[key])
+		if err != nil {
+			errs.Add(fmt.Errorf("config file %s: %s: %w", path, key, err))
+		}
+	}
+
+	return errs.Err()
+}
+
+func fieldByKey(fields []*field, key string) *field {
+	for _, f := range fields {
+		if f.key == key {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// Print - write resolved config as YAML with secrets redacted.
+func Print(w io.Writer, src interface{}) error {
+	fields, err := collectFields(src)
+	if err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	err = enc.Encode(nest(fields))
+	if err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// IsHelp - flag parsing stopped on -h.
+func IsHelp(err error) bool {
+	return errors.Is(err, flag.ErrHelp)
+}
+
+// Require - report keys of loaded src left empty, for fields shared by binaries
+// that need them under different conditions and so cannot be tagged required.
+func Require(src interface{}, keys ...string) error {
+	fields, err := collectFields(src)
+	if err != nil {
+		return err
+	}
+
+	errs := Errors{}
+
+	for _, key := range keys {
+		f := fieldByKey(fields, key)
+		if f == nil {
+			return fmt.Errorf("config: unknown key %q", key)
+		}
+
+		if f.isZero() {
+			errs.Add(f.requiredError())
+		}
+	}
+
+	return errs.Err()
+}
+
+func (f *field) printable() interface{} {
+	if f.secret && !f.isZero() {
+		return redacted
+	}
+
+	return f.format()
+}
+
+func (f *field) flagUsage() string {
+	usage := f.usage
+	if len(f.envs) > 0 {
+		usage += " (env " + f.envs[0] + ")"
+	}
+
+	if f.def != "" {
+		usage += " (default " + f.def + ")"
+	}
+
+	return usage
+}
+
+func (f *field) requiredError() error {
+	return fmt.Errorf("%s: required, %s", f.key, f.sources())
+}
+
+func (f *field) sources() string {
+	src := f.key + " in config file or -" + f.key + " flag"
+	if len(f.envs) > 0 {
+		src = f.envs[0] + " env, " + src
+	}
+
+	return "set " + src
+}
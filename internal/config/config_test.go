@@ -0,0 +1,239 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	HTTP struct {
+		Addr    string        `config:"http.addr" env:"TEST_HTTP_ADDR" required:"true"`
+		Timeout time.Duration `config:"http.timeout" env:"TEST_HTTP_TIMEOUT,TEST_TIMEOUT" default:"1s"`
+	}
+	Brokers []string `config:"kafka.brokers" env:"TEST_KAFKA_BROKERS" default:"default:9092"`
+	Size    int      `config:"size" env:"TEST_SIZE" default:"1"`
+	Name    string   `config:"name" env:"TEST_NAME" default:"default"`
+	Secret  string   `config:"secret" env:"TEST_SECRET" secret:"true"`
+}
+
+func TestLoadLayering(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+http:
+  addr: file:8080
+  timeout: 2s
+kafka:
+  brokers: [file-1:9092, file-2:9092]
+size: 2
+`)
+
+	t.Setenv("CONFIG_FILE", file)
+	t.Setenv("TEST_HTTP_TIMEOUT", "3s")
+	t.Setenv("TEST_SIZE", "3")
+
+	cfg := &testConfig{}
+
+	_, err := Load("test", cfg, []string{"-size", "4"})
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "default", got: cfg.Name, want: "default"},
+		{name: "file over default", got: cfg.Brokers, want: []string{"file-1:9092", "file-2:9092"}},
+		{name: "file", got: cfg.HTTP.Addr, want: "file:8080"},
+		{name: "env over file", got: cfg.HTTP.Timeout, want: 3 * time.Second},
+		{name: "flag over env", got: cfg.Size, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Fatalf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTOMLFile(t *testing.T) {
+	file := writeFile(t, "config.toml", `
+name = "toml"
+
+[http]
+addr = "toml:8080"
+`)
+
+	cfg := &testConfig{}
+
+	_, err := Load("test", cfg, []string{"-config", file})
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if cfg.Name != "toml" || cfg.HTTP.Addr != "toml:8080" {
+		t.Fatalf("got name %q, addr %q, want values from toml file", cfg.Name, cfg.HTTP.Addr)
+	}
+}
+
+func TestLoadLegacyEnv(t *testing.T) {
+	t.Setenv("TEST_HTTP_ADDR", ":8080")
+	t.Setenv("TEST_TIMEOUT", "5")
+
+	cfg := &testConfig{}
+
+	_, err := Load("test", cfg, nil)
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if cfg.HTTP.Timeout != 5*time.Second {
+		t.Fatalf("got timeout %v, want %v", cfg.HTTP.Timeout, 5*time.Second)
+	}
+
+	t.Setenv("TEST_HTTP_TIMEOUT", "6s")
+
+	_, err = Load("test", cfg, nil)
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if cfg.HTTP.Timeout != 6*time.Second {
+		t.Fatalf("got timeout %v, first env must win, want %v", cfg.HTTP.Timeout, 6*time.Second)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+http:
+  addr: file:8080
+unknown: 1
+`)
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		errs []string
+	}{
+		{
+			name: "required",
+			errs: []string{"http.addr: required"},
+		},
+		{
+			name: "every bad value is reported",
+			args: []string{"-http.addr", ":8080", "-size", "many"},
+			env:  map[string]string{"TEST_HTTP_TIMEOUT": "soon"},
+			errs: []string{"env TEST_HTTP_TIMEOUT", "flag -size"},
+		},
+		{
+			name: "unknown file key",
+			args: []string{"-config", file},
+			errs: []string{`unknown key "unknown"`},
+		},
+		{
+			name: "unsupported file format",
+			args: []string{"-config", writeFile(t, "config.json", "{}"), "-http.addr", ":8080"},
+			errs: []string{"unsupported format"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := Load("test", &testConfig{}, tt.args)
+			if err == nil {
+				t.Fatalf("got no error, want %v", tt.errs)
+			}
+
+			for _, want := range tt.errs {
+				if !strings.Contains(err.Error(), want) {
+					t.Fatalf("got error %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadPartial(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+http:
+  addr: file:8080
+unknown: 1
+`)
+
+	cfg := &testConfig{}
+
+	opts, err := LoadPartial("test", cfg, []string{"-config", file, "-name", "flag", "user", "purge", "-uid", "1"})
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if cfg.HTTP.Addr != "file:8080" || cfg.Name != "flag" {
+		t.Fatalf("got addr %q, name %q, want file:8080, flag", cfg.HTTP.Addr, cfg.Name)
+	}
+
+	want := []string{"user", "purge", "-uid", "1"}
+	if !reflect.DeepEqual(opts.Args, want) {
+		t.Fatalf("got args %v, want %v", opts.Args, want)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	cfg := &testConfig{}
+	cfg.Name = "set"
+
+	err := Require(cfg, "name", "secret", "kafka.brokers")
+	if err == nil {
+		t.Fatal("got no error, want secret and kafka.brokers required")
+	}
+
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 || !strings.Contains(errs[0].Error(), "TEST_SECRET env") {
+		t.Fatalf("got %v, want secret and kafka.brokers required", err)
+	}
+
+	err = Require(cfg, "missing")
+	if err == nil {
+		t.Fatal("got no error for unknown key")
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := &testConfig{}
+	cfg.HTTP.Addr = ":8080"
+	cfg.Secret = "hunter2"
+
+	b := &strings.Builder{}
+
+	err := Print(b, cfg)
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if strings.Contains(b.String(), "hunter2") || !strings.Contains(b.String(), redacted) {
+		t.Fatalf("got %q, want secret redacted", b.String())
+	}
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+
+	return path
+}
@@ -0,0 +1,40 @@
+package config
+
+import "strings"
+
+// Errors - every problem found while loading config.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+
+	for i := range e {
+		msgs = append(msgs, e[i].Error())
+	}
+
+	return "invalid config:\n  " + strings.Join(msgs, "\n  ")
+}
+
+// Add - collect error, nil is ignored.
+func (e *Errors) Add(err error) {
+	if err == nil {
+		return
+	}
+
+	if errs, ok := err.(Errors); ok {
+		*e = append(*e, errs...)
+
+		return
+	}
+
+	*e = append(*e, err)
+}
+
+// Err - nil when nothing was collected.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
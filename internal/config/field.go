@@ -0,0 +1,195 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// field - config struct leaf described by tags:
+//
+//	config:"http.read_timeout"  file key and flag name
+//	env:"HTTP_READ_TIMEOUT"     comma separated env names, first set one wins
+//	default:"10s"               default value
+//	required:"true"             value must be provided by any source
+//	secret:"true"               value is redacted when printed
+//	usage:"..."                 flag help
+type field struct {
+	key      string
+	envs     []string
+	def      string
+	required bool
+	secret   bool
+	usage    string
+	value    reflect.Value
+	set      bool
+}
+
+// collectFields - walk nested structs of dst and collect tagged leaves.
+func collectFields(dst interface{}) ([]*field, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: expected pointer to struct, got %T", dst)
+	}
+
+	var fields []*field
+
+	err := walk(v.Elem(), &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func walk(v reflect.Value, fields *[]*field) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fv := v.Field(i)
+
+		key, ok := sf.Tag.Lookup("config")
+		if !ok {
+			if sf.Type.Kind() == reflect.Struct && sf.Type != durationType {
+				err := walk(fv, fields)
+				if err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if !fv.CanSet() {
+			return fmt.Errorf("config: field %s must be exported", sf.Name)
+		}
+
+		f := &field{
+			key:      key,
+			def:      sf.Tag.Get("default"),
+			required: sf.Tag.Get("required") == "true",
+			secret:   sf.Tag.Get("secret") == "true",
+			usage:    sf.Tag.Get("usage"),
+			value:    fv,
+		}
+
+		if envs := sf.Tag.Get("env"); envs != "" {
+			f.envs = strings.Split(envs, ",")
+		}
+
+		*fields = append(*fields, f)
+	}
+
+	return nil
+}
+
+// parse - set field from string representation.
+func (f *field) parse(s string) error {
+	err := setValue(f.value, s)
+	if err != nil {
+		return err
+	}
+
+	f.set = true
+
+	return nil
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := parseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", s)
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+
+		v.SetInt(i)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+
+		v.Set(reflect.ValueOf(splitList(s)))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// parseDuration - parse Go duration, plain integers are seconds.
+func parseDuration(s string) (time.Duration, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q, expected e.g. \"1.5s\" or seconds", s)
+	}
+
+	return d, nil
+}
+
+func splitList(s string) []string {
+	parts := strings.Split(s, ",")
+	list := make([]string, 0, len(parts))
+
+	for i := range parts {
+		p := strings.TrimSpace(parts[i])
+		if p == "" {
+			continue
+		}
+
+		list = append(list, p)
+	}
+
+	return list
+}
+
+// format - string representation of field value.
+func (f *field) format() interface{} {
+	v := f.value
+
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+
+	return v.Interface()
+}
+
+func (f *field) isZero() bool {
+	return f.value.IsZero() || (f.value.Kind() == reflect.Slice && f.value.Len() == 0)
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readFile - read YAML or TOML file into flat dotted keys.
+func readFile(path string) (map[string]string, error) {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return parseYAML(bb)
+	case ".toml":
+		return parseTOML(bb)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, expected .yaml, .yml or .toml", path)
+	}
+}
+
+func parseYAML(bb []byte) (map[string]string, error) {
+	doc := map[string]interface{}{}
+
+	err := yaml.Unmarshal(bb, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("parse yaml config: %w", err)
+	}
+
+	flat := map[string]string{}
+
+	err = flatten("", doc, flat)
+	if err != nil {
+		return nil, err
+	}
+
+	return flat, nil
+}
+
+func flatten(prefix string, v interface{}, flat map[string]string) error {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			err := flatten(joinKey(prefix, k), child, flat)
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items := make([]string, 0, len(val))
+
+		for i := range val {
+			if _, ok := val[i].(map[string]interface{}); ok {
+				return fmt.Errorf("%s: lists of maps are not supported", prefix)
+			}
+
+			items = append(items, fmt.Sprint(val[i]))
+		}
+
+		flat[prefix] = strings.Join(items, ",")
+	case []map[string]interface{}:
+		return fmt.Errorf("%s: lists of maps are not supported", prefix)
+	case nil:
+		flat[prefix] = ""
+	default:
+		flat[prefix] = fmt.Sprint(val)
+	}
+
+	return nil
+}
+
+func joinKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+// nest - build nested map from dotted keys for printing.
+func nest(fields []*field) map[string]interface{} {
+	root := map[string]interface{}{}
+
+	for _, f := range fields {
+		parts := strings.Split(f.key, ".")
+		node := root
+
+		for _, p := range parts[:len(parts)-1] {
+			child, ok := node[p].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[p] = child
+			}
+
+			node = child
+		}
+
+		node[parts[len(parts)-1]] = f.printable()
+	}
+
+	return root
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
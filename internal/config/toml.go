@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseTOML - parse TOML document into flat dotted keys, tables become key prefixes.
+func parseTOML(bb []byte) (map[string]string, error) {
+	doc := map[string]interface{}{}
+
+	_, err := toml.Decode(string(bb), &doc)
+	if err != nil {
+		return nil, fmt.Errorf("parse toml config: %w", err)
+	}
+
+	flat := map[string]string{}
+
+	err = flatten("", doc, flat)
+	if err != nil {
+		return nil, err
+	}
+
+	return flat, nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		want  map[string]string
+		isErr bool
+	}{
+		{
+			name: "tables and dotted keys",
+			doc: `
+top = "a"
+
+[http]
+addr = ":8080"
+read_timeout = "10s"
+
+[users.cache]
+backend = "redis"
+ttl.extra = 1
+`,
+			want: map[string]string{
+				"top":                   "a",
+				"http.addr":             ":8080",
+				"http.read_timeout":     "10s",
+				"users.cache.backend":   "redis",
+				"users.cache.ttl.extra": "1",
+			},
+		},
+		{
+			name: "strings and escapes",
+			doc: `
+basic = "a \"quoted\" \t value \u00e9"
+path = "C:\\" # comment after escaped backslash
+literal = 'C:\path\no\escapes'
+hash = "not # a comment"
+literal_hash = 'not # a comment'
+multiline = """
+first
+second"""
+`,
+			want: map[string]string{
+				"basic":        "a \"quoted\" \t value \u00e9",
+				"path":         `C:\`,
+				"literal":      `C:\path\no\escapes`,
+				"hash":         "not # a comment",
+				"literal_hash": "not # a comment",
+				"multiline":    "first\nsecond",
+			},
+		},
+		{
+			name: "numbers and booleans",
+			doc: `
+size = 1_048_576
+ratio = 0.8
+enabled = true
+`,
+			want: map[string]string{
+				"size":    "1048576",
+				"ratio":   "0.8",
+				"enabled": "true",
+			},
+		},
+		{
+			name: "arrays",
+			doc: `
+brokers = [
+  "kafka-1:9092", # first
+  "kafka-2:9092",
+]
+numbers = [1, 2]
+empty = []
+`,
+			want: map[string]string{
+				"brokers": "kafka-1:9092,kafka-2:9092",
+				"numbers": "1,2",
+				"empty":   "",
+			},
+		},
+		{
+			name: "inline tables and quoted keys",
+			doc: `
+redis = { addr = "redis:6379", db = 1 }
+"events".'timeout' = "10s"
+`,
+			want: map[string]string{
+				"redis.addr":     "redis:6379",
+				"redis.db":       "1",
+				"events.timeout": "10s",
+			},
+		},
+		{
+			name: "comments",
+			doc: `
+# whole line
+[mongo] # after table
+db_name = "friends" # after value
+`,
+			want: map[string]string{
+				"mongo.db_name": "friends",
+			},
+		},
+		{name: "array of tables", doc: "[[servers]]\naddr = \"a\"\n", isErr: true},
+		{name: "unterminated table", doc: "[http\n", isErr: true},
+		{name: "missing equals", doc: "addr\n", isErr: true},
+		{name: "empty key", doc: "= 1\n", isErr: true},
+		{name: "missing value", doc: "addr =\n", isErr: true},
+		{name: "unterminated string", doc: "addr = \"a\n", isErr: true},
+		{name: "unterminated literal string", doc: "addr = 'a\n", isErr: true},
+		{name: "invalid escape", doc: "addr = \"\\q\"\n", isErr: true},
+		{name: "duplicate key", doc: "addr = 1\naddr = 2\n", isErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML([]byte(tt.doc))
+			if (err != nil) != tt.isErr {
+				t.Fatalf("got error %v, want error %v", err, tt.isErr)
+			}
+
+			if tt.isErr {
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+const (
+	// MaxNicknameLen - max friend nickname length in characters.
+	MaxNicknameLen = 64
+	// MaxNoteLen - max friend note length in characters.
+	MaxNoteLen = 500
+)
+
+// Annotation - private nickname and note user attached to friend, visible only to that user.
+type Annotation struct {
+	Nickname  string
+	Note      string
+	UpdatedAt time.Time
+}
+
+func (a *Annotation) validate() error {
+	if !utf8.ValidString(a.Nickname) || !utf8.ValidString(a.Note) {
+		return fmt.Errorf("annotation must be valid UTF-8")
+	}
+
+	if utf8.RuneCountInString(a.Nickname) > MaxNicknameLen {
+		return fmt.Errorf("nickname must be at most %d characters", MaxNicknameLen)
+	}
+
+	if strings.IndexFunc(a.Nickname, unicode.IsControl) >= 0 {
+		return fmt.Errorf("nickname cannot contain control characters")
+	}
+
+	if utf8.RuneCountInString(a.Note) > MaxNoteLen {
+		return fmt.Errorf("note must be at most %d characters", MaxNoteLen)
+	}
+
+	return nil
+}
+
+// SetAnnotation - set friend annotation, empty nickname and note remove it.
+// Ok is true for invalid annotation or when users are not friends.
+func (s *ServiceImpl) SetAnnotation(ctx context.Context, uid string, friendID string, a Annotation) (bool, error) {
+	a.Nickname = strings.TrimSpace(a.Nickname)
+	a.Note = strings.TrimSpace(a.Note)
+
+	err := a.validate()
+	if err != nil {
+		return true, err
+	}
+
+	var stored *mongo.Annotation
+
+	if a.Nickname != "" || a.Note != "" {
+		stored = &mongo.Annotation{
+			Nickname: a.Nickname,
+			Note:     a.Note,
+		}
+	}
+
+	ok, err := s.db.SetAnnotation(ctx, uid, friendID, stored)
+	if err == nil {
+		s.friendsCache.delete(uid)
+	}
+
+	return ok, err
+}
+
+func convertAnnotation(a *mongo.Annotation) *Annotation {
+	if a == nil {
+		return nil
+	}
+
+	return &Annotation{
+		Nickname:  a.Nickname,
+		Note:      a.Note,
+		UpdatedAt: a.UpdatedAt,
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+// AntiSpamConfig - friend requests anti-spam config, unanswered requests
+// are capped by outgoing pending limit of user.
+type AntiSpamConfig struct {
+	// DeclineRatioThreshold - declined to sent requests ratio flagging user.
+	DeclineRatioThreshold float64
+	// DeclineRatioMinSample - sent requests needed before ratio is evaluated.
+	DeclineRatioMinSample int64
+}
+
+// checkRequestLimits - check sender rate limits.
+func (s *ServiceImpl) checkRequestLimits(ctx context.Context, from string) error {
+	allowed, retryAfter, err := s.requestsLimiter.Allow(ctx, from)
+	if err != nil {
+		// limiter store outage must not block users
+		rateLimitErrorsTotal.Inc()
+	} else if !allowed {
+		friendRequestsRateLimitedTotal.WithLabelValues("rate").Inc()
+		s.recordAbuseSignals(ctx, from, mongo.AbuseSignalsDelta{RateLimited: 1})
+
+		return &RateLimitError{
+			Reason:     "too many friend requests",
+			RetryAfter: retryAfter,
+		}
+	}
+
+	return nil
+}
+
+// recordPendingLimit - count sender hitting own outgoing pending limit as anti-spam rejection.
+func (s *ServiceImpl) recordPendingLimit(ctx context.Context, from string, err error) {
+	var limitErr *LimitError
+
+	if !errors.As(err, &limitErr) || limitErr.Limit != mongo.LimitOutgoingPending || limitErr.UID != from {
+		return
+	}
+
+	friendRequestsRateLimitedTotal.WithLabelValues("pending").Inc()
+	s.recordAbuseSignals(ctx, from, mongo.AbuseSignalsDelta{RateLimited: 1})
+}
+
+// recordAbuseSignals - best effort update of sender abuse counters,
+// flags sender once declined requests ratio crosses threshold.
+func (s *ServiceImpl) recordAbuseSignals(ctx context.Context, uid string, delta mongo.AbuseSignalsDelta) {
+	signals, err := s.db.IncAbuseSignals(ctx, uid, delta)
+	if err != nil {
+		abuseSignalErrorsTotal.Inc()
+
+		return
+	}
+
+	if signals.HighDeclineRatio || signals.RequestsSent < s.antiSpam.DeclineRatioMinSample {
+		return
+	}
+
+	if signals.DeclineRatio() < s.antiSpam.DeclineRatioThreshold {
+		return
+	}
+
+	err = s.db.FlagHighDeclineRatio(ctx, uid)
+	if err != nil {
+		abuseSignalErrorsTotal.Inc()
+
+		return
+	}
+
+	usersFlaggedTotal.WithLabelValues("high_decline_ratio").Inc()
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+const maxAuditEntries = 1000
+
+// AuditEntry - friendship graph change record.
+type AuditEntry struct {
+	Actor  string
+	Source string
+	Action string
+	UID    string
+	Target string
+	At     time.Time
+}
+
+// AuditFilter - audit query, zero time bounds are open, zero limit means max.
+type AuditFilter struct {
+	UID   string
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+func (s *ServiceImpl) GetAuditEntries(ctx context.Context, f AuditFilter) ([]*AuditEntry, error) {
+	if f.Limit <= 0 || f.Limit > maxAuditEntries {
+		f.Limit = maxAuditEntries
+	}
+
+	entries, err := s.db.GetAuditEntries(ctx, mongo.AuditFilter{
+		UID:   f.UID,
+		From:  f.From,
+		To:    f.To,
+		Limit: int64(f.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]*AuditEntry, 0, len(entries))
+
+	for _, e := range entries {
+		converted = append(converted, &AuditEntry{
+			Actor:  e.Actor,
+			Source: e.Source,
+			Action: e.Action,
+			UID:    e.UID,
+			Target: e.Target,
+			At:     e.At,
+		})
+	}
+
+	return converted, nil
+}
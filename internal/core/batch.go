@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+const (
+	// MaxRequestsBatch - max friend requests handled by one batch call.
+	MaxRequestsBatch = 100
+
+	// requestsChunkSize - friend requests handled in one transaction.
+	requestsChunkSize = 20
+)
+
+// Friend requests batch statuses.
+const (
+	BatchStatusAccepted       = "accepted"
+	BatchStatusDeclined       = "declined"
+	BatchStatusNotFound       = "not_found"
+	BatchStatusAlreadyFriends = "already_friends"
+	BatchStatusLimitExceeded  = "limit_exceeded"
+)
+
+// BatchResult - outcome of one friend request of batch.
+type BatchResult struct {
+	ID     string
+	Status string
+}
+
+// AcceptFriendRequests - accept requests sent to uid by ids, one transaction per chunk.
+// Chunks committed before an error stay applied.
+func (s *ServiceImpl) AcceptFriendRequests(ctx context.Context, uid string, ids []string) ([]*BatchResult, error) {
+	results, err := s.handleFriendRequests(ctx, ids, BatchStatusAccepted, func(chunk []string) ([]mongo.RequestOutcome, error) {
+		return s.db.AcceptFriendRequests(ctx, uid, chunk)
+	})
+
+	accepted := []string{uid}
+
+	for _, res := range results {
+		if res.Status != BatchStatusAccepted {
+			continue
+		}
+
+		accepted = append(accepted, res.ID)
+
+		friendRequestsAcceptedTotal.Inc()
+		s.recordAbuseSignals(ctx, res.ID, mongo.AbuseSignalsDelta{RequestsAccepted: 1})
+	}
+
+	s.friendsCache.delete(accepted...)
+
+	return results, err
+}
+
+// DeclineFriendRequests - decline requests sent to uid by ids, one transaction per chunk.
+// Chunks committed before an error stay applied.
+func (s *ServiceImpl) DeclineFriendRequests(ctx context.Context, uid string, ids []string) ([]*BatchResult, error) {
+	results, err := s.handleFriendRequests(ctx, ids, BatchStatusDeclined, func(chunk []string) ([]mongo.RequestOutcome, error) {
+		return s.db.DeclineFriendRequests(ctx, uid, chunk)
+	})
+
+	for _, res := range results {
+		if res.Status != BatchStatusDeclined {
+			continue
+		}
+
+		friendRequestsDeclinedTotal.Inc()
+		s.recordAbuseSignals(ctx, res.ID, mongo.AbuseSignalsDelta{RequestsDeclined: 1})
+	}
+
+	return results, err
+}
+
+// handleFriendRequests - split ids into chunks and convert outcomes of handled ones,
+// results cover committed chunks only.
+func (s *ServiceImpl) handleFriendRequests(ctx context.Context, ids []string, doneStatus string, handle func([]string) ([]mongo.RequestOutcome, error)) ([]*BatchResult, error) {
+	if len(ids) > MaxRequestsBatch {
+		return nil, fmt.Errorf("at most %d requests can be handled at once", MaxRequestsBatch)
+	}
+
+	results := make([]*BatchResult, 0, len(ids))
+
+	for start := 0; start < len(ids); start += requestsChunkSize {
+		end := start + requestsChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		chunk := ids[start:end]
+
+		outcomes, err := handle(chunk)
+		if err != nil {
+			return results, fmt.Errorf("handle requests %d-%d: %w", start, end-1, err)
+		}
+
+		for i, outcome := range outcomes {
+			status := doneStatus
+
+			switch outcome {
+			case mongo.OutcomeNotFound:
+				status = BatchStatusNotFound
+			case mongo.OutcomeAlreadyFriends:
+				status = BatchStatusAlreadyFriends
+			case mongo.OutcomeLimitExceeded:
+				status = BatchStatusLimitExceeded
+			}
+
+			results = append(results, &BatchResult{
+				ID:     chunk[i],
+				Status: status,
+			})
+		}
+	}
+
+	return results, nil
+}
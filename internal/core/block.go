@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Block - block user, friendship, requests and follows between users are dropped.
+func (s *ServiceImpl) Block(ctx context.Context, from string, to string) (bool, error) {
+	ok, err := s.db.Block(ctx, from, to)
+	if err == nil {
+		s.friendsCache.delete(from, to)
+	}
+
+	return ok, err
+}
+
+// Unblock - unblock user, dropped relations are not restored.
+func (s *ServiceImpl) Unblock(ctx context.Context, from string, to string) (bool, error) {
+	return s.db.Unblock(ctx, from, to)
+}
+
+// GetBlocked - get users blocked by user, Since is block time.
+func (s *ServiceImpl) GetBlocked(ctx context.Context, uid string) ([]*User, error) {
+	blocks, err := s.db.GetBlocks(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(blocks))
+	times := make(map[string]time.Time, len(blocks))
+
+	for _, b := range blocks {
+		ids = append(ids, b.Blocked)
+		times[b.Blocked] = b.CreatedAt
+	}
+
+	uu, err := s.getUsers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range uu {
+		u.Since = times[u.ID]
+	}
+
+	sortUsers(uu, SortRecent, func(u *User) time.Time { return u.Since })
+
+	return uu, nil
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxCountsBatch - max users whose counts are read by one call.
+const MaxCountsBatch = 100
+
+// Counts - number of user friends and pending requests.
+type Counts struct {
+	UID             string
+	Friends         int64
+	IncomingPending int64
+	OutgoingPending int64
+}
+
+// GetCounts - get user friends and pending requests counts.
+func (s *ServiceImpl) GetCounts(ctx context.Context, uid string) (*Counts, error) {
+	counts, err := s.db.GetCounts(ctx, []string{uid})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Counts{
+		UID:             uid,
+		Friends:         counts[0].Friends,
+		IncomingPending: counts[0].IncomingPending,
+		OutgoingPending: counts[0].OutgoingPending,
+	}, nil
+}
+
+// GetFriendsCounts - get friends counts of users in uids order,
+// pending requests are private and left zero.
+func (s *ServiceImpl) GetFriendsCounts(ctx context.Context, uids []string) ([]*Counts, error) {
+	if len(uids) > MaxCountsBatch {
+		return nil, fmt.Errorf("at most %d users can be counted at once", MaxCountsBatch)
+	}
+
+	counts, err := s.db.GetCounts(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]*Counts, 0, len(counts))
+
+	for _, c := range counts {
+		converted = append(converted, &Counts{
+			UID:     c.UID,
+			Friends: c.Friends,
+		})
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,21 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+// LimitError - change would take user over friends or pending requests limit.
+type LimitError = mongo.LimitError
+
+// RateLimitError - user exceeded friend requests limits.
+type RateLimitError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", e.Reason, e.RetryAfter.Round(time.Second))
+}
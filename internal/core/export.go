@@ -0,0 +1,361 @@
+package core
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+	"github.com/daniilty/sharenote-friends/internal/slice"
+)
+
+// exportNotes - data kinds that are part of the request but not kept by the service.
+var exportNotes = []string{
+	"groups are not stored by friends service",
+	"timestamps of friendships and requests created before they were recorded are unknown",
+}
+
+const (
+	// exportUnknownTime - CSV value of unknown timestamp.
+	exportUnknownTime = "unknown"
+	// exportChunkSize - users resolved and written at once.
+	exportChunkSize = 500
+)
+
+// exportChunkFunc - handle chunk of section user ids and their timestamps.
+type exportChunkFunc func(ids []string, times map[string]time.Time) error
+
+type exportSection struct {
+	name string
+	// timeColumn - name of timestamp column, also selects User field it is read from.
+	timeColumn string
+	// forEach - call fn for every chunk of section users.
+	forEach func(ctx context.Context, fn exportChunkFunc) error
+	// decorate - set section specific fields of resolved user, optional.
+	decorate func(u *User)
+}
+
+type exportUser struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Since       *time.Time `json:"since,omitempty"`
+	RequestedAt *time.Time `json:"requested_at,omitempty"`
+	IsFavorite  bool       `json:"is_favorite,omitempty"`
+	Nickname    string     `json:"nickname,omitempty"`
+	Note        string     `json:"note,omitempty"`
+}
+
+func (e *exportSection) time(u *User) time.Time {
+	if e.timeColumn == "since" {
+		return u.Since
+	}
+
+	return u.RequestedAt
+}
+
+func (e *exportSection) setTime(u *User, at time.Time) {
+	if e.timeColumn == "since" {
+		u.Since = at
+	} else {
+		u.RequestedAt = at
+	}
+}
+
+// Export - write zip archive with per section CSV files and export.json,
+// users are resolved and written in chunks. Zip entries cannot be written
+// in parallel, so JSON is spooled to temporary file and added last.
+func (s *ServiceImpl) Export(ctx context.Context, uid string, w io.Writer) error {
+	sections, err := s.getExportSections(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	spool, err := os.CreateTemp("", "friends-export-*.json")
+	if err != nil {
+		return fmt.Errorf("create export spool: %w", err)
+	}
+
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+
+	jw := bufio.NewWriter(spool)
+
+	err = writeExportJSONHeader(jw, uid)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+
+	for _, section := range sections {
+		err = s.writeExportSection(ctx, archive, jw, section)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(jw, "}\n")
+	if err != nil {
+		return err
+	}
+
+	err = jw.Flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = spool.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	f, err := archive.Create("export.json")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, spool)
+	if err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func (s *ServiceImpl) getExportSections(ctx context.Context, uid string) ([]*exportSection, error) {
+	friends, err := s.db.GetFriends(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get friends: %w", err)
+	}
+
+	incoming, err := s.db.GetFriendRequests(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get friend requests: %w", err)
+	}
+
+	outgoing, err := s.db.GetOutgoingFriendRequests(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get outgoing friend requests: %w", err)
+	}
+
+	outgoingRequestedAt, err := s.db.GetOutgoingRequestedAt(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get outgoing friend requests time: %w", err)
+	}
+
+	blocks, err := s.db.GetBlocks(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get blocks: %w", err)
+	}
+
+	blockedIDs := make([]string, 0, len(blocks))
+	blockedSince := make(map[string]time.Time, len(blocks))
+
+	for _, b := range blocks {
+		blockedIDs = append(blockedIDs, b.Blocked)
+		blockedSince[b.Blocked] = b.CreatedAt
+	}
+
+	return []*exportSection{
+		{
+			name:       "friends",
+			timeColumn: "since",
+			forEach:    exportIDs(friends.FriendIDs, friends.Since),
+			// favorites and annotations are kept for friends only
+			decorate: func(u *User) {
+				u.IsFavorite = slice.ContainsString(friends.FavoriteIDs, u.ID)
+				u.Annotation = convertAnnotation(friends.Annotations[u.ID])
+			},
+		},
+		{
+			name:       "incoming_requests",
+			timeColumn: "requested_at",
+			forEach:    exportIDs(incoming.FriendIDs, incoming.RequestedAt),
+		},
+		{
+			name:       "outgoing_requests",
+			timeColumn: "requested_at",
+			forEach:    exportIDs(outgoing, outgoingRequestedAt),
+		},
+		{
+			name:       "following",
+			timeColumn: "since",
+			forEach:    s.exportFollows(uid, false),
+		},
+		{
+			name:       "followers",
+			timeColumn: "since",
+			forEach:    s.exportFollows(uid, true),
+		},
+		{
+			name:       "blocked",
+			timeColumn: "since",
+			forEach:    exportIDs(blockedIDs, blockedSince),
+		},
+	}, nil
+}
+
+// exportIDs - section users from ids already read.
+func exportIDs(ids []string, times map[string]time.Time) func(context.Context, exportChunkFunc) error {
+	return func(_ context.Context, fn exportChunkFunc) error {
+		for start := 0; start < len(ids); start += exportChunkSize {
+			end := start + exportChunkSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+
+			err := fn(ids[start:end], times)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// exportFollows - section users read from follow edges of user chunk by chunk.
+func (s *ServiceImpl) exportFollows(uid string, followers bool) func(context.Context, exportChunkFunc) error {
+	return func(ctx context.Context, fn exportChunkFunc) error {
+		filter := mongo.FollowFilter{UID: uid, Followers: followers}
+
+		return s.db.ForEachFollows(ctx, filter, exportChunkSize, func(follows []*mongo.Follow) error {
+			return fn(followsToIDs(follows, followers))
+		})
+	}
+}
+
+// writeExportSection - write section CSV entry and append section to export.json.
+func (s *ServiceImpl) writeExportSection(ctx context.Context, archive *zip.Writer, jw io.Writer, section *exportSection) error {
+	f, err := archive.Create(section.name + ".csv")
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(f)
+
+	err = cw.Write([]string{"id", "name", section.timeColumn})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(jw, `,%q:[`, section.name)
+	if err != nil {
+		return err
+	}
+
+	first := true
+
+	err = section.forEach(ctx, func(ids []string, times map[string]time.Time) error {
+		users, err := s.getUsers(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("resolve %s: %w", section.name, err)
+		}
+
+		for _, u := range users {
+			section.setTime(u, knownTime(times, u.ID))
+
+			if section.decorate != nil {
+				section.decorate(u)
+			}
+
+			if !first {
+				_, err = io.WriteString(jw, ",")
+				if err != nil {
+					return err
+				}
+			}
+
+			first = false
+
+			err = writeExportJSONUser(jw, u)
+			if err != nil {
+				return err
+			}
+
+			at := exportUnknownTime
+			if t := section.time(u); !t.IsZero() {
+				at = t.Format(time.RFC3339)
+			}
+
+			err = cw.Write([]string{u.ID, u.Name, at})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+
+	err = cw.Error()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(jw, "]")
+
+	return err
+}
+
+// writeExportJSONHeader - write export.json object without closing brace,
+// sections are appended to it one user at a time.
+func writeExportJSONHeader(jw io.Writer, uid string) error {
+	header, err := json.Marshal(map[string]interface{}{
+		"uid":          uid,
+		"generated_at": time.Now().UTC(),
+		"notes":        exportNotes,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = jw.Write(header[:len(header)-1])
+
+	return err
+}
+
+func writeExportJSONUser(jw io.Writer, u *User) error {
+	eu := &exportUser{
+		ID:          u.ID,
+		Name:        u.Name,
+		Since:       timePtr(u.Since),
+		RequestedAt: timePtr(u.RequestedAt),
+		IsFavorite:  u.IsFavorite,
+	}
+
+	if u.Annotation != nil {
+		eu.Nickname = u.Annotation.Nickname
+		eu.Note = u.Annotation.Note
+	}
+
+	bb, err := json.Marshal(eu)
+	if err != nil {
+		return err
+	}
+
+	_, err = jw.Write(bb)
+
+	return err
+}
+
+// timePtr - nil for zero time, so unknown timestamps are omitted from JSON.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+const (
+	defaultFollowsLimit = 100
+	maxFollowsLimit     = 1000
+)
+
+// FollowsPage - followers and following list page, newest first.
+// Zero Before is open, zero Limit means default.
+type FollowsPage struct {
+	// Before - exclusive upper bound of follow time, use Since of last user to get next page.
+	Before time.Time
+	Limit  int
+}
+
+func (s *ServiceImpl) Follow(ctx context.Context, from string, to string) (bool, error) {
+	return s.db.Follow(ctx, from, to)
+}
+
+func (s *ServiceImpl) Unfollow(ctx context.Context, from string, to string) (bool, error) {
+	return s.db.Unfollow(ctx, from, to)
+}
+
+// RemoveFollower - make follower stop following user.
+func (s *ServiceImpl) RemoveFollower(ctx context.Context, uid string, follower string) (bool, error) {
+	return s.db.RemoveFollower(ctx, uid, follower)
+}
+
+// GetFollowers - get users following user, Since is follow time.
+func (s *ServiceImpl) GetFollowers(ctx context.Context, uid string, page FollowsPage) ([]*User, error) {
+	return s.getFollows(ctx, uid, true, page)
+}
+
+// GetFollowing - get users followed by user, Since is follow time.
+func (s *ServiceImpl) GetFollowing(ctx context.Context, uid string, page FollowsPage) ([]*User, error) {
+	return s.getFollows(ctx, uid, false, page)
+}
+
+func (s *ServiceImpl) getFollows(ctx context.Context, uid string, followers bool, page FollowsPage) ([]*User, error) {
+	if page.Limit <= 0 {
+		page.Limit = defaultFollowsLimit
+	}
+
+	if page.Limit > maxFollowsLimit {
+		page.Limit = maxFollowsLimit
+	}
+
+	follows, err := s.db.GetFollows(ctx, mongo.FollowFilter{
+		UID:       uid,
+		Followers: followers,
+		Before:    page.Before,
+		Limit:     int64(page.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids, times := followsToIDs(follows, followers)
+
+	uu, err := s.getUsers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range uu {
+		u.Since = times[u.ID]
+	}
+
+	// users service does not have to keep requested order
+	sortUsers(uu, SortRecent, func(u *User) time.Time { return u.Since })
+
+	return uu, nil
+}
+
+// followsToIDs - ids of other side of follow edges and their follow time.
+func followsToIDs(follows []*mongo.Follow, followers bool) ([]string, map[string]time.Time) {
+	ids := make([]string, 0, len(follows))
+	times := make(map[string]time.Time, len(follows))
+
+	for _, f := range follows {
+		id := f.Followee
+		if followers {
+			id = f.Follower
+		}
+
+		ids = append(ids, id)
+		times[id] = f.CreatedAt
+	}
+
+	return ids, times
+}
@@ -2,88 +2,175 @@ package core
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"time"
 
+	"github.com/daniilty/sharenote-friends/internal/mongo"
 	"github.com/daniilty/sharenote-friends/internal/slice"
-	schema "github.com/daniilty/sharenote-grpc-schema"
 )
 
-func (s *ServiceImpl) GetFriendRequests(ctx context.Context, uid string) ([]*User, error) {
+func (s *ServiceImpl) GetFriendRequests(ctx context.Context, uid string, opts ListOptions) ([]*User, error) {
 	reqs, err := s.db.GetFriendRequests(ctx, uid)
 	if err != nil {
 		return nil, err
 	}
 
-	usersResp, err := s.usersClient.GetUsers(ctx, &schema.GetUsersRequest{
-		Ids: reqs.FriendIDs,
-	})
+	uu, err := s.getUsers(ctx, reqs.FriendIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	return convertPBUsersToInner(usersResp.GetUsers()), nil
+	for _, u := range uu {
+		u.RequestedAt = knownTime(reqs.RequestedAt, u.ID)
+	}
+
+	sortUsers(uu, opts.Sort, func(u *User) time.Time { return u.RequestedAt })
+
+	return uu, nil
 }
 
 func (s *ServiceImpl) RequestFriend(ctx context.Context, from string, to string) (bool, error) {
-	reqs, err := s.db.GetFriendRequests(ctx, to)
+	err := s.checkRequestLimits(ctx, from)
 	if err != nil {
-		return false, err
-	}
+		var rateLimitErr *RateLimitError
 
-	if slice.ContainsString(reqs.FriendIDs, from) {
-		return true, fmt.Errorf("user is already in friend requests")
+		return errors.As(err, &rateLimitErr), err
 	}
 
-	reqs.FriendIDs = append(reqs.FriendIDs, from)
-
-	err = s.db.UpdateFriendRequests(ctx, reqs)
+	ok, err := s.db.RequestFriend(ctx, from, to)
 	if err != nil {
-		return false, err
+		s.recordPendingLimit(ctx, from, err)
+
+		return ok, err
 	}
 
+	friendRequestsSentTotal.Inc()
+	s.recordAbuseSignals(ctx, from, mongo.AbuseSignalsDelta{RequestsSent: 1})
+
 	return true, nil
 }
 
 func (s *ServiceImpl) DeclineFriendRequest(ctx context.Context, from string, to string) (bool, error) {
-	reqs, err := s.db.GetFriendRequests(ctx, to)
+	ok, err := s.db.DeclineFriendRequest(ctx, from, to)
 	if err != nil {
-		return false, err
+		return ok, err
 	}
 
-	if !slice.ContainsString(reqs.FriendIDs, from) {
-		return true, fmt.Errorf("user is not in friend requests")
+	friendRequestsDeclinedTotal.Inc()
+	s.recordAbuseSignals(ctx, from, mongo.AbuseSignalsDelta{RequestsDeclined: 1})
+
+	return true, nil
+}
+
+func (s *ServiceImpl) GetFriends(ctx context.Context, uid string, opts ListOptions) ([]*User, error) {
+	var (
+		entries []*searchEntry
+		cached  bool
+	)
+
+	// only searches are served from cache, plain list reflects changes immediately
+	if opts.Query != "" {
+		entries, cached = s.friendsCache.get(uid)
 	}
 
-	reqs.FriendIDs = slice.RemoveString(reqs.FriendIDs, from)
+	if !cached {
+		var err error
 
-	err = s.db.UpdateFriendRequests(ctx, reqs)
-	if err != nil {
-		return false, err
+		entries, err = s.getFriendEntries(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.Query != "" {
+			s.friendsCache.set(uid, entries)
+		}
 	}
 
-	return true, nil
+	query := foldName(opts.Query)
+	uu := make([]*User, 0, len(entries))
+
+	for _, e := range entries {
+		if query != "" && !matchesQuery(e.name, query) && !matchesQuery(e.nickname, query) {
+			continue
+		}
+
+		if !inSinceRange(e.user.Since, opts) {
+			continue
+		}
+
+		if opts.FavoritesOnly && !e.user.IsFavorite {
+			continue
+		}
+
+		uu = append(uu, e.user)
+	}
+
+	sortUsers(uu, opts.Sort, func(u *User) time.Time { return u.Since })
+
+	return uu, nil
 }
 
-func (s *ServiceImpl) GetFriends(ctx context.Context, uid string) ([]*User, error) {
+// getFriendEntries - resolve user friends with friendship creation time.
+func (s *ServiceImpl) getFriendEntries(ctx context.Context, uid string) ([]*searchEntry, error) {
 	friends, err := s.db.GetFriends(ctx, uid)
 	if err != nil {
 		return nil, err
 	}
 
-	usersResp, err := s.usersClient.GetUsers(ctx, &schema.GetUsersRequest{
-		Ids: friends.FriendIDs,
-	})
+	uu, err := s.getUsers(ctx, friends.FriendIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	return convertPBUsersToInner(usersResp.GetUsers()), nil
+	entries := make([]*searchEntry, 0, len(uu))
+
+	for _, u := range uu {
+		u.Since = knownTime(friends.Since, u.ID)
+		u.IsFavorite = slice.ContainsString(friends.FavoriteIDs, u.ID)
+		u.Annotation = convertAnnotation(friends.Annotations[u.ID])
+
+		entry := &searchEntry{
+			user: u,
+			name: foldName(u.Name),
+		}
+
+		if u.Annotation != nil {
+			entry.nickname = foldName(u.Annotation.Nickname)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// SetFavorite - mark or unmark friend as favorite, ok is true when users are not friends.
+func (s *ServiceImpl) SetFavorite(ctx context.Context, uid string, friendID string, favorite bool) (bool, error) {
+	ok, err := s.db.SetFavorite(ctx, uid, friendID, favorite)
+	if err == nil {
+		s.friendsCache.delete(uid)
+	}
+
+	return ok, err
 }
 
 func (s *ServiceImpl) AddFriend(ctx context.Context, from string, to string) (bool, error) {
-	return s.db.AddFriend(ctx, from, to)
+	ok, err := s.db.AddFriend(ctx, from, to)
+	if err == nil {
+		s.friendsCache.delete(from, to)
+		friendRequestsAcceptedTotal.Inc()
+		s.recordAbuseSignals(ctx, from, mongo.AbuseSignalsDelta{RequestsAccepted: 1})
+	}
+
+	return ok, err
 }
 
 func (s *ServiceImpl) RemoveFriend(ctx context.Context, from string, to string) (bool, error) {
-	return s.db.RemoveFriend(ctx, from, to)
+	ok, err := s.db.RemoveFriend(ctx, from, to)
+	if err == nil {
+		s.friendsCache.delete(from, to)
+		friendsRemovedTotal.Inc()
+	}
+
+	return ok, err
 }
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+// Invite modes.
+const (
+	// InviteModeFriend - redeeming makes users friends.
+	InviteModeFriend = mongo.InviteModeFriend
+	// InviteModeRequest - redeeming sends friend request to inviter.
+	InviteModeRequest = mongo.InviteModeRequest
+)
+
+// ErrInvalidInvite - invite token is malformed, forged or expired.
+var ErrInvalidInvite = errors.New("invite is invalid or expired")
+
+// InviteConfig - invite links config.
+type InviteConfig struct {
+	// Secret - HMAC key signing invite tokens.
+	Secret []byte
+	// TTL - invite lifetime when none is requested.
+	TTL time.Duration
+	// MaxTTL - longest allowed invite lifetime.
+	MaxTTL time.Duration
+	// MaxActive - max not expired invites per user, zero disables check.
+	MaxActive int
+}
+
+// InviteOptions - new invite options, zero TTL means default one.
+type InviteOptions struct {
+	Mode      string
+	SingleUse bool
+	TTL       time.Duration
+}
+
+// Invite - invite link issued by user.
+type Invite struct {
+	ID        string
+	Token     string
+	Inviter   string
+	Mode      string
+	SingleUse bool
+	Redeemed  int
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// CreateInvite - issue signed invite, ok is true for invalid options.
+func (s *ServiceImpl) CreateInvite(ctx context.Context, uid string, opts InviteOptions) (*Invite, bool, error) {
+	if opts.Mode != InviteModeFriend && opts.Mode != InviteModeRequest {
+		return nil, true, fmt.Errorf("invite mode must be one of %q, %q", InviteModeFriend, InviteModeRequest)
+	}
+
+	if opts.TTL == 0 {
+		opts.TTL = s.invites.TTL
+	}
+
+	if opts.TTL < 0 || opts.TTL > s.invites.MaxTTL {
+		return nil, true, fmt.Errorf("invite lifetime must be positive and at most %s", s.invites.MaxTTL)
+	}
+
+	if s.invites.MaxActive > 0 {
+		active, err := s.db.GetInvites(ctx, uid)
+		if err != nil {
+			return nil, false, fmt.Errorf("get invites: %w", err)
+		}
+
+		if len(active) >= s.invites.MaxActive {
+			return nil, true, fmt.Errorf("at most %d active invites are allowed, revoke unused ones", s.invites.MaxActive)
+		}
+	}
+
+	id, err := newInviteID()
+	if err != nil {
+		return nil, false, err
+	}
+
+	createdAt := time.Now().UTC().Truncate(time.Millisecond)
+
+	invite := &mongo.Invite{
+		ID:        id,
+		Inviter:   uid,
+		Mode:      opts.Mode,
+		SingleUse: opts.SingleUse,
+		CreatedAt: createdAt,
+		ExpiresAt: createdAt.Add(opts.TTL),
+	}
+
+	err = s.db.InsertInvite(ctx, invite)
+	if err != nil {
+		return nil, false, fmt.Errorf("insert invite: %w", err)
+	}
+
+	return s.convertInvite(invite), true, nil
+}
+
+// GetInvites - get active invites issued by user.
+func (s *ServiceImpl) GetInvites(ctx context.Context, uid string) ([]*Invite, error) {
+	invites, err := s.db.GetInvites(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]*Invite, 0, len(invites))
+
+	for _, invite := range invites {
+		converted = append(converted, s.convertInvite(invite))
+	}
+
+	return converted, nil
+}
+
+// RevokeInvite - delete invite issued by user, ok is true when it does not exist.
+func (s *ServiceImpl) RevokeInvite(ctx context.Context, uid string, id string) (bool, error) {
+	return s.db.RevokeInvite(ctx, uid, id)
+}
+
+// RedeemInvite - verify token and make user friend of inviter or send request to inviter
+// depending on invite mode, ok is true for client errors.
+func (s *ServiceImpl) RedeemInvite(ctx context.Context, uid string, token string) (*Invite, bool, error) {
+	id, err := s.verifyInviteToken(token, time.Now())
+	if err != nil {
+		return nil, true, err
+	}
+
+	invite, ok, err := s.db.RedeemInvite(ctx, id, uid)
+	if err != nil {
+		return nil, ok, err
+	}
+
+	if invite.Mode == InviteModeFriend {
+		s.friendsCache.delete(uid, invite.Inviter)
+	} else {
+		friendRequestsSentTotal.Inc()
+	}
+
+	return s.convertInvite(invite), true, nil
+}
+
+func (s *ServiceImpl) convertInvite(invite *mongo.Invite) *Invite {
+	return &Invite{
+		ID:        invite.ID,
+		Token:     s.signInviteToken(invite.ID, invite.ExpiresAt),
+		Inviter:   invite.Inviter,
+		Mode:      invite.Mode,
+		SingleUse: invite.SingleUse,
+		Redeemed:  invite.Redeemed,
+		CreatedAt: invite.CreatedAt,
+		ExpiresAt: invite.ExpiresAt,
+	}
+}
+
+// signInviteToken - build "id.expiry.signature" token, expiry is unix seconds,
+// so forged or expired tokens are rejected without reading invite.
+func (s *ServiceImpl) signInviteToken(id string, expiresAt time.Time) string {
+	payload := id + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.inviteMAC(payload))
+}
+
+// verifyInviteToken - check token signature and expiry, returns invite id.
+func (s *ServiceImpl) verifyInviteToken(token string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidInvite
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, s.inviteMAC(parts[0]+"."+parts[1])) {
+		return "", ErrInvalidInvite
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !now.Before(time.Unix(expiresAt, 0)) {
+		return "", ErrInvalidInvite
+	}
+
+	return parts[0], nil
+}
+
+func (s *ServiceImpl) inviteMAC(payload string) []byte {
+	mac := hmac.New(sha256.New, s.invites.Secret)
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
+
+func newInviteID() (string, error) {
+	bb := make([]byte, 16)
+
+	_, err := rand.Read(bb)
+	if err != nil {
+		return "", fmt.Errorf("generate invite id: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bb), nil
+}
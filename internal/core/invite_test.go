@@ -0,0 +1,54 @@
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyInviteToken(t *testing.T) {
+	s := &ServiceImpl{invites: InviteConfig{Secret: []byte("0123456789abcdef0123456789abcdef")}}
+	other := &ServiceImpl{invites: InviteConfig{Secret: []byte("fedcba9876543210fedcba9876543210")}}
+
+	now := time.Unix(1700000000, 0)
+	valid := s.signInviteToken("invite", now.Add(time.Hour))
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+		err   error
+	}{
+		{name: "valid", token: valid, now: now},
+		{name: "expired", token: valid, now: now.Add(time.Hour), err: ErrInvalidInvite},
+		{name: "other secret", token: other.signInviteToken("invite", now.Add(time.Hour)), now: now, err: ErrInvalidInvite},
+		{name: "forged id", token: "other." + parts[1] + "." + parts[2], now: now, err: ErrInvalidInvite},
+		{name: "forged expiry", token: parts[0] + ".1800000000." + parts[2], now: now, err: ErrInvalidInvite},
+		{name: "empty", token: "", now: now, err: ErrInvalidInvite},
+		{name: "missing signature", token: parts[0] + "." + parts[1], now: now, err: ErrInvalidInvite},
+		{name: "extra part", token: valid + ".x", now: now, err: ErrInvalidInvite},
+		{name: "signature not base64", token: parts[0] + "." + parts[1] + ".!!", now: now, err: ErrInvalidInvite},
+		{name: "signed bad expiry", token: signedPayload(s, "invite.soon"), now: now, err: ErrInvalidInvite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := s.verifyInviteToken(tt.token, tt.now)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+
+			if tt.err == nil && id != "invite" {
+				t.Fatalf("got id %q, want %q", id, "invite")
+			}
+		})
+	}
+}
+
+// signedPayload - token with valid signature over arbitrary payload.
+func signedPayload(s *ServiceImpl, payload string) string {
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.inviteMAC(payload))
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	friendRequestsSentTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "friends_requests_sent_total",
+		Help: "Friend requests sent.",
+	})
+	friendRequestsAcceptedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "friends_requests_accepted_total",
+		Help: "Friend requests accepted.",
+	})
+	friendRequestsDeclinedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "friends_requests_declined_total",
+		Help: "Friend requests declined.",
+	})
+	friendsRemovedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "friends_removed_total",
+		Help: "Friendships removed by users.",
+	})
+	friendRequestsRateLimitedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "friends_requests_rate_limited_total",
+		Help: "Friend requests rejected by anti-spam limits by limit.",
+	}, []string{"limit"})
+	rateLimitErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "friends_rate_limit_errors_total",
+		Help: "Rate limiter store errors, requests are allowed on error.",
+	})
+	abuseSignalErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "friends_abuse_signal_errors_total",
+		Help: "Failed abuse signals updates.",
+	})
+	pathSearchesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "friends_path_searches_total",
+		Help: "Shortest friendship path searches by result.",
+	}, []string{"result"})
+	usersFlaggedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "friends_users_flagged_total",
+		Help: "Users flagged by abuse signals by reason.",
+	}, []string{"reason"})
+)
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Path search results.
+const (
+	pathResultFound          = "found"
+	pathResultNotFound       = "not_found"
+	pathResultBudgetExceeded = "budget_exceeded"
+)
+
+// PathConfig - shortest friendship path search config.
+type PathConfig struct {
+	// MaxDepth - max friendships in path.
+	MaxDepth int
+	// MaxVisited - max users visited by one search, search gives up after it.
+	MaxVisited int
+}
+
+// pathSide - one side of bidirectional search.
+type pathSide struct {
+	// parent - visited user id to id of user it was reached from.
+	parent map[string]string
+	// depth - visited user id to friendships from side start.
+	depth    map[string]int
+	frontier []string
+	level    int
+}
+
+func newPathSide(start string) *pathSide {
+	return &pathSide{
+		parent:   map[string]string{start: ""},
+		depth:    map[string]int{start: 0},
+		frontier: []string{start},
+	}
+}
+
+// chain - ids from side start to id.
+func (p *pathSide) chain(id string) []string {
+	ids := []string{}
+
+	for ; id != ""; id = p.parent[id] {
+		ids = append(ids, id)
+	}
+
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+
+	return ids
+}
+
+// FindPath - get shortest friendship chain from user to target, both included,
+// nil when users are not connected within configured depth and visit budget.
+// Users blocked by or blocking either of them and users hiding their connections
+// are never part of chain.
+func (s *ServiceImpl) FindPath(ctx context.Context, from string, to string) ([]*User, error) {
+	ids, result, err := s.findPathIDs(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	pathSearchesTotal.WithLabelValues(result).Inc()
+
+	if ids == nil {
+		return nil, nil
+	}
+
+	uu, err := s.getUsers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// users service may reorder or skip users, chain order matters here
+	byID := make(map[string]*User, len(uu))
+
+	for _, u := range uu {
+		byID[u.ID] = u
+	}
+
+	path := make([]*User, 0, len(ids))
+
+	for _, id := range ids {
+		u, ok := byID[id]
+		if !ok {
+			u = &User{ID: id}
+		}
+
+		path = append(path, u)
+	}
+
+	return path, nil
+}
+
+// findPathIDs - bidirectional BFS, each step expands whole level of smaller frontier
+// with one friends read and picks shortest chain among users met on that level.
+func (s *ServiceImpl) findPathIDs(ctx context.Context, from string, to string) ([]string, string, error) {
+	skip, err := s.getPathSkippedIDs(ctx, from, to)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if skip[from] || skip[to] {
+		return nil, pathResultNotFound, nil
+	}
+
+	hidden, err := s.db.GetHiddenConnectionsIDs(ctx, []string{to})
+	if err != nil {
+		return nil, "", fmt.Errorf("get hidden connections: %w", err)
+	}
+
+	fwd := newPathSide(from)
+	bwd := newPathSide(to)
+	visited := 2
+
+	// friends of hiding target are not read, it is only reached from users it is friend of
+	if len(hidden) > 0 {
+		bwd.frontier = nil
+	}
+
+	// empty backward frontier on first level is hiding target, not exhausted side
+	for fwd.level+bwd.level < s.path.MaxDepth && len(fwd.frontier) > 0 && (len(bwd.frontier) > 0 || bwd.level == 0) {
+		side, other := fwd, bwd
+		if len(bwd.frontier) > 0 && len(bwd.frontier) < len(fwd.frontier) {
+			side, other = bwd, fwd
+		}
+
+		friends, err := s.db.GetFriendIDs(ctx, side.frontier)
+		if err != nil {
+			return nil, "", err
+		}
+
+		hidden, err := s.getPathHiddenIDs(ctx, side, friends, from, to)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var (
+			meet     string
+			meetRest int
+			next     []string
+		)
+
+	expand:
+		for _, uid := range side.frontier {
+			for _, id := range friends[uid] {
+				_, ok := side.parent[id]
+				if ok || skip[id] || hidden[id] {
+					continue
+				}
+
+				side.parent[id] = uid
+				side.depth[id] = side.level + 1
+				next = append(next, id)
+				visited++
+
+				rest, ok := other.depth[id]
+				if ok && (meet == "" || rest < meetRest) {
+					meet, meetRest = id, rest
+				}
+
+				if visited >= s.path.MaxVisited {
+					break expand
+				}
+			}
+		}
+
+		side.frontier = next
+		side.level++
+
+		if meet != "" {
+			ids := fwd.chain(meet)
+			back := bwd.chain(meet)
+
+			for i := len(back) - 2; i >= 0; i-- {
+				ids = append(ids, back[i])
+			}
+
+			return ids, pathResultFound, nil
+		}
+
+		if visited >= s.path.MaxVisited {
+			return nil, pathResultBudgetExceeded, nil
+		}
+	}
+
+	return nil, pathResultNotFound, nil
+}
+
+// getPathSkippedIDs - users blocked by or blocking user or target,
+// user and target are in it when they blocked each other.
+func (s *ServiceImpl) getPathSkippedIDs(ctx context.Context, from string, to string) (map[string]bool, error) {
+	skip := map[string]bool{}
+
+	for _, uid := range []string{from, to} {
+		ids, err := s.db.GetBlockedIDs(ctx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("get blocked ids: %w", err)
+		}
+
+		for _, id := range ids {
+			skip[id] = true
+		}
+	}
+
+	return skip, nil
+}
+
+// getPathHiddenIDs - not yet visited friends of side frontier hiding their connections,
+// user and target are never hidden from search.
+func (s *ServiceImpl) getPathHiddenIDs(ctx context.Context, side *pathSide, friends map[string][]string, from string, to string) (map[string]bool, error) {
+	ids := []string{}
+
+	for _, uid := range side.frontier {
+		for _, id := range friends[uid] {
+			_, ok := side.parent[id]
+			if !ok && id != from && id != to {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	hidden := map[string]bool{}
+
+	if len(ids) == 0 {
+		return hidden, nil
+	}
+
+	hiddenIDs, err := s.db.GetHiddenConnectionsIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get hidden connections: %w", err)
+	}
+
+	for _, id := range hiddenIDs {
+		hidden[id] = true
+	}
+
+	return hidden, nil
+}
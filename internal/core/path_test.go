@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+// fakePathDB - friendship graph, blocks and privacy settings kept in memory.
+type fakePathDB struct {
+	mongo.DB
+
+	friends map[string][]string
+	blocks  [][2]string
+	hidden  map[string]bool
+}
+
+// newFakePathDB - graph with friendship between both ids of every edge.
+func newFakePathDB(edges ...[2]string) *fakePathDB {
+	db := &fakePathDB{
+		friends: map[string][]string{},
+		hidden:  map[string]bool{},
+	}
+
+	for _, e := range edges {
+		db.friends[e[0]] = append(db.friends[e[0]], e[1])
+		db.friends[e[1]] = append(db.friends[e[1]], e[0])
+	}
+
+	return db
+}
+
+func (f *fakePathDB) GetFriendIDs(_ context.Context, uids []string) (map[string][]string, error) {
+	ids := map[string][]string{}
+
+	for _, uid := range uids {
+		if friends, ok := f.friends[uid]; ok {
+			ids[uid] = friends
+		}
+	}
+
+	return ids, nil
+}
+
+func (f *fakePathDB) GetBlockedIDs(_ context.Context, uid string) ([]string, error) {
+	ids := []string{}
+
+	for _, b := range f.blocks {
+		if b[0] == uid {
+			ids = append(ids, b[1])
+		}
+
+		if b[1] == uid {
+			ids = append(ids, b[0])
+		}
+	}
+
+	return ids, nil
+}
+
+func (f *fakePathDB) GetHiddenConnectionsIDs(_ context.Context, uids []string) ([]string, error) {
+	ids := []string{}
+
+	for _, uid := range uids {
+		if f.hidden[uid] {
+			ids = append(ids, uid)
+		}
+	}
+
+	return ids, nil
+}
+
+func TestFindPathIDs(t *testing.T) {
+	// a - b - c - d - e - f line
+	line := [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}, {"d", "e"}, {"e", "f"}}
+	// leaves of a make forward frontier larger, so backward side is expanded
+	leaves := append([][2]string{{"a", "x1"}, {"a", "x2"}, {"a", "x3"}}, line...)
+	// a - b - c and longer a - y - z - c route
+	detour := [][2]string{{"a", "b"}, {"b", "c"}, {"a", "y"}, {"y", "z"}, {"z", "c"}}
+
+	tests := []struct {
+		name   string
+		db     *fakePathDB
+		cfg    PathConfig
+		from   string
+		to     string
+		ids    []string
+		result string
+	}{
+		{
+			name:   "friends",
+			db:     newFakePathDB(line...),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "b",
+			ids:    []string{"a", "b"},
+			result: pathResultFound,
+		},
+		{
+			name:   "met by forward side",
+			db:     newFakePathDB(line...),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "c",
+			ids:    []string{"a", "b", "c"},
+			result: pathResultFound,
+		},
+		{
+			name:   "met by backward side",
+			db:     newFakePathDB(leaves...),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "c",
+			ids:    []string{"a", "b", "c"},
+			result: pathResultFound,
+		},
+		{
+			name:   "chain on both sides of meeting point",
+			db:     newFakePathDB(leaves...),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "f",
+			ids:    []string{"a", "b", "c", "d", "e", "f"},
+			result: pathResultFound,
+		},
+		{
+			name:   "reversed chain",
+			db:     newFakePathDB(leaves...),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "f",
+			to:     "a",
+			ids:    []string{"f", "e", "d", "c", "b", "a"},
+			result: pathResultFound,
+		},
+		{
+			name:   "depth limit",
+			db:     newFakePathDB(line...),
+			cfg:    PathConfig{MaxDepth: 4, MaxVisited: 100},
+			from:   "a",
+			to:     "f",
+			result: pathResultNotFound,
+		},
+		{
+			name:   "path of max depth",
+			db:     newFakePathDB(line...),
+			cfg:    PathConfig{MaxDepth: 5, MaxVisited: 100},
+			from:   "a",
+			to:     "f",
+			ids:    []string{"a", "b", "c", "d", "e", "f"},
+			result: pathResultFound,
+		},
+		{
+			name:   "visit budget",
+			db:     newFakePathDB(leaves...),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 5},
+			from:   "a",
+			to:     "f",
+			result: pathResultBudgetExceeded,
+		},
+		{
+			name:   "not connected",
+			db:     newFakePathDB([2]string{"a", "b"}, [2]string{"c", "d"}),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "d",
+			result: pathResultNotFound,
+		},
+		{
+			name: "hidden user is skipped",
+			db: func() *fakePathDB {
+				db := newFakePathDB(detour...)
+				db.hidden["b"] = true
+
+				return db
+			}(),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "c",
+			ids:    []string{"a", "y", "z", "c"},
+			result: pathResultFound,
+		},
+		{
+			name: "user blocked by target is skipped",
+			db: func() *fakePathDB {
+				db := newFakePathDB(detour...)
+				db.blocks = [][2]string{{"c", "b"}}
+
+				return db
+			}(),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "c",
+			ids:    []string{"a", "y", "z", "c"},
+			result: pathResultFound,
+		},
+		{
+			name: "user blocking caller is skipped",
+			db: func() *fakePathDB {
+				db := newFakePathDB(detour...)
+				db.blocks = [][2]string{{"b", "a"}}
+
+				return db
+			}(),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "c",
+			ids:    []string{"a", "y", "z", "c"},
+			result: pathResultFound,
+		},
+		{
+			name: "blocked target",
+			db: func() *fakePathDB {
+				db := newFakePathDB(line...)
+				db.blocks = [][2]string{{"a", "c"}}
+
+				return db
+			}(),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "c",
+			result: pathResultNotFound,
+		},
+		{
+			name: "hidden target is reached from its friend",
+			db: func() *fakePathDB {
+				db := newFakePathDB(leaves...)
+				db.hidden["c"] = true
+
+				return db
+			}(),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "c",
+			ids:    []string{"a", "b", "c"},
+			result: pathResultFound,
+		},
+		{
+			name: "hidden caller still searches own friends",
+			db: func() *fakePathDB {
+				db := newFakePathDB(line...)
+				db.hidden["a"] = true
+
+				return db
+			}(),
+			cfg:    PathConfig{MaxDepth: 6, MaxVisited: 100},
+			from:   "a",
+			to:     "d",
+			ids:    []string{"a", "b", "c", "d"},
+			result: pathResultFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &ServiceImpl{db: tt.db, path: tt.cfg}
+
+			ids, result, err := s.findPathIDs(context.Background(), tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("find path: %v", err)
+			}
+
+			if result != tt.result {
+				t.Fatalf("got result %q, want %q", result, tt.result)
+			}
+
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Fatalf("got path %v, want %v", ids, tt.ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// SearchConfig - friends search config.
+type SearchConfig struct {
+	// CacheSize - number of users whose resolved friends are cached, zero disables cache.
+	CacheSize int
+	// CacheTTL - resolved friends lifetime, renamed users are found by new name after it.
+	CacheTTL time.Duration
+}
+
+// searchEntry - friend with folded name and nickname used for matching.
+type searchEntry struct {
+	user     *User
+	name     string
+	nickname string
+}
+
+type friendsCacheItem struct {
+	uid       string
+	entries   []*searchEntry
+	expiresAt time.Time
+}
+
+// friendsCache - in-process LRU cache of resolved friends by user id,
+// lets search skip users service while user keeps typing.
+// Nil cache is valid and caches nothing.
+type friendsCache struct {
+	mu sync.Mutex
+
+	size int
+	ttl  time.Duration
+
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+func newFriendsCache(cfg SearchConfig) *friendsCache {
+	if cfg.CacheSize <= 0 || cfg.CacheTTL <= 0 {
+		return nil
+	}
+
+	return &friendsCache{
+		size:  cfg.CacheSize,
+		ttl:   cfg.CacheTTL,
+		ll:    list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+func (c *friendsCache) get(uid string) ([]*searchEntry, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[uid]
+	if !ok {
+		return nil, false
+	}
+
+	item := el.Value.(*friendsCacheItem)
+	if time.Now().After(item.expiresAt) {
+		c.remove(el)
+
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+
+	return item.entries, true
+}
+
+func (c *friendsCache) set(uid string, entries []*searchEntry) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item := &friendsCacheItem{
+		uid:       uid,
+		entries:   entries,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+
+	el, ok := c.items[uid]
+	if ok {
+		el.Value = item
+		c.ll.MoveToFront(el)
+
+		return
+	}
+
+	c.items[uid] = c.ll.PushFront(item)
+
+	if c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+// delete - drop users entries after their friends changed.
+func (c *friendsCache) delete(uids ...string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, uid := range uids {
+		el, ok := c.items[uid]
+		if ok {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *friendsCache) remove(el *list.Element) {
+	item := c.ll.Remove(el).(*friendsCacheItem)
+	delete(c.items, item.uid)
+}
+
+// foldName - lower case name without diacritics and repeated spaces.
+func foldName(s string) string {
+	// transformers keep state, so chain is built per call
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}
+
+// matchesQuery - folded query is prefix of folded name or of one of its words.
+func matchesQuery(name string, query string) bool {
+	return name != "" && (strings.HasPrefix(name, query) || strings.Contains(name, " "+query))
+}
+
+// inSinceRange - friendship creation time is within range, zero bounds are open.
+// Unknown creation time matches only unbounded range.
+func inSinceRange(since time.Time, opts ListOptions) bool {
+	if opts.SinceFrom.IsZero() && opts.SinceTo.IsZero() {
+		return true
+	}
+
+	if since.IsZero() {
+		return false
+	}
+
+	if !opts.SinceFrom.IsZero() && since.Before(opts.SinceFrom) {
+		return false
+	}
+
+	return opts.SinceTo.IsZero() || since.Before(opts.SinceTo)
+}
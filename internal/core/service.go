@@ -2,34 +2,94 @@ package core
 
 import (
 	"context"
+	"io"
 
 	"github.com/daniilty/sharenote-friends/internal/mongo"
+	"github.com/daniilty/sharenote-friends/internal/ratelimit"
 	schema "github.com/daniilty/sharenote-grpc-schema"
 )
 
 type Service interface {
 	// GetFriendRequests - get hser friend request uids.
-	GetFriendRequests(context.Context, string) ([]*User, error)
+	GetFriendRequests(context.Context, string, ListOptions) ([]*User, error)
 	// RequestFriend - add friend request to user.
 	RequestFriend(context.Context, string, string) (bool, error)
 	// DeclineFriendRequest decline request from some user.
 	DeclineFriendRequest(context.Context, string, string) (bool, error)
 	// GetFriends - get user friends.
-	GetFriends(context.Context, string) ([]*User, error)
+	GetFriends(context.Context, string, ListOptions) ([]*User, error)
+	// SetFavorite - mark or unmark friend as favorite.
+	SetFavorite(context.Context, string, string, bool) (bool, error)
+	// SetAnnotation - set or remove friend private nickname and note.
+	SetAnnotation(context.Context, string, string, Annotation) (bool, error)
 	// AddFriend - add friend from friend request.
 	AddFriend(context.Context, string, string) (bool, error)
+	// AcceptFriendRequests - accept requests from several users, reports outcome per user.
+	AcceptFriendRequests(context.Context, string, []string) ([]*BatchResult, error)
+	// DeclineFriendRequests - decline requests from several users, reports outcome per user.
+	DeclineFriendRequests(context.Context, string, []string) ([]*BatchResult, error)
 	// RemoveFriend - remove friend.
 	RemoveFriend(context.Context, string, string) (bool, error)
+	// Follow - follow user, no approval is needed.
+	Follow(context.Context, string, string) (bool, error)
+	// Unfollow - stop following user.
+	Unfollow(context.Context, string, string) (bool, error)
+	// RemoveFollower - make user stop following user.
+	RemoveFollower(context.Context, string, string) (bool, error)
+	// Block - block user, drops friendship, requests and follows between users.
+	Block(context.Context, string, string) (bool, error)
+	// Unblock - unblock user.
+	Unblock(context.Context, string, string) (bool, error)
+	// GetBlocked - get users blocked by user.
+	GetBlocked(context.Context, string) ([]*User, error)
+	// GetFollowers - get users following user.
+	GetFollowers(context.Context, string, FollowsPage) ([]*User, error)
+	// GetFollowing - get users followed by user.
+	GetFollowing(context.Context, string, FollowsPage) ([]*User, error)
+	// GetSettings - get user privacy settings.
+	GetSettings(context.Context, string) (*Settings, error)
+	// SetSettings - replace user privacy settings.
+	SetSettings(context.Context, string, Settings) error
+	// GetCounts - get user friends and pending requests counts.
+	GetCounts(context.Context, string) (*Counts, error)
+	// GetFriendsCounts - get friends counts of several users.
+	GetFriendsCounts(context.Context, []string) ([]*Counts, error)
+	// FindPath - get shortest friendship chain between users.
+	FindPath(context.Context, string, string) ([]*User, error)
+	// GetUsers - resolve user ids, names are empty while users service is degraded.
+	GetUsers(context.Context, []string) ([]*User, error)
+	// Export - write user graph archive.
+	Export(context.Context, string, io.Writer) error
+	// CreateInvite - issue invite link for user.
+	CreateInvite(context.Context, string, InviteOptions) (*Invite, bool, error)
+	// GetInvites - get active invites issued by user.
+	GetInvites(context.Context, string) ([]*Invite, error)
+	// RevokeInvite - delete invite issued by user.
+	RevokeInvite(context.Context, string, string) (bool, error)
+	// RedeemInvite - apply invite token for user.
+	RedeemInvite(context.Context, string, string) (*Invite, bool, error)
+	// GetAuditEntries - get newest first graph changes involving user.
+	GetAuditEntries(context.Context, AuditFilter) ([]*AuditEntry, error)
 }
 
 type ServiceImpl struct {
-	usersClient schema.UsersClient
-	db          mongo.DB
+	usersClient     schema.UsersClient
+	db              mongo.DB
+	requestsLimiter ratelimit.Limiter
+	antiSpam        AntiSpamConfig
+	friendsCache    *friendsCache
+	invites         InviteConfig
+	path            PathConfig
 }
 
-func NewService(db mongo.DB, usersClient schema.UsersClient) Service {
+func NewService(db mongo.DB, usersClient schema.UsersClient, requestsLimiter ratelimit.Limiter, antiSpam AntiSpamConfig, search SearchConfig, invites InviteConfig, path PathConfig) Service {
 	return &ServiceImpl{
-		usersClient: usersClient,
-		db:          db,
+		usersClient:     usersClient,
+		db:              db,
+		requestsLimiter: requestsLimiter,
+		antiSpam:        antiSpam,
+		friendsCache:    newFriendsCache(search),
+		invites:         invites,
+		path:            path,
 	}
 }
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"context"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+// Settings - user privacy settings.
+type Settings struct {
+	// HideConnections - user friends are not used to find paths between other users.
+	HideConnections bool
+}
+
+// GetSettings - get user privacy settings.
+func (s *ServiceImpl) GetSettings(ctx context.Context, uid string) (*Settings, error) {
+	settings, err := s.db.GetSettings(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Settings{HideConnections: settings.HideConnections}, nil
+}
+
+// SetSettings - replace user privacy settings.
+func (s *ServiceImpl) SetSettings(ctx context.Context, uid string, settings Settings) error {
+	return s.db.SetSettings(ctx, &mongo.Settings{
+		UID:             uid,
+		HideConnections: settings.HideConnections,
+	})
+}
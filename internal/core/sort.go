@@ -0,0 +1,73 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+)
+
+// Friends and requests list orders.
+const (
+	// SortName - by name, case insensitive.
+	SortName = "name"
+	// SortRecent - newest first, unknown timestamps last.
+	SortRecent = "recent"
+)
+
+// ListOptions - friends and requests list options, empty sort keeps stored order.
+// Filters apply to friends list only.
+type ListOptions struct {
+	Sort string
+	// Query - name or nickname prefix, matched case and diacritics insensitive against every word.
+	Query string
+	// SinceFrom - inclusive lower bound of friendship creation time, zero is open.
+	SinceFrom time.Time
+	// SinceTo - exclusive upper bound of friendship creation time, zero is open.
+	SinceTo time.Time
+	// FavoritesOnly - list only friends marked as favorite.
+	FavoritesOnly bool
+}
+
+// IsValidSort - check sort is supported.
+func IsValidSort(s string) bool {
+	return s == "" || s == SortName || s == SortRecent
+}
+
+// knownTime - stored timestamp, zero when it is missing or backfilled sentinel.
+func knownTime(times map[string]time.Time, id string) time.Time {
+	at, ok := times[id]
+	if !ok || at.Equal(mongo.UnknownTime) {
+		return time.Time{}
+	}
+
+	return at
+}
+
+// sortUsers - order users in place, at returns timestamp used by recent sort.
+func sortUsers(uu []*User, order string, at func(*User) time.Time) {
+	byName := func(i int, j int) bool {
+		li, lj := strings.ToLower(uu[i].Name), strings.ToLower(uu[j].Name)
+		if li != lj {
+			return li < lj
+		}
+
+		return uu[i].ID < uu[j].ID
+	}
+
+	switch order {
+	case SortName:
+		sort.SliceStable(uu, byName)
+	case SortRecent:
+		sort.SliceStable(uu, func(i int, j int) bool {
+			ti, tj := at(uu[i]), at(uu[j])
+			if !ti.Equal(tj) {
+				// zero time is before any known one, so unknown goes last
+				return ti.After(tj)
+			}
+
+			return byName(i, j)
+		})
+	}
+}
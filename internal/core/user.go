@@ -1,10 +1,57 @@
 package core
 
-import schema "github.com/daniilty/sharenote-grpc-schema"
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/usersclient"
+	schema "github.com/daniilty/sharenote-grpc-schema"
+)
 
 type User struct {
 	ID   string
 	Name string
+	// Since - friendship or follow creation time, zero when unknown.
+	Since time.Time
+	// RequestedAt - friend request creation time, zero when unknown.
+	RequestedAt time.Time
+	// IsFavorite - friend is marked as close by user.
+	IsFavorite bool
+	// Annotation - private nickname and note, nil when there is none.
+	Annotation *Annotation
+}
+
+func (s *ServiceImpl) GetUsers(ctx context.Context, ids []string) ([]*User, error) {
+	return s.getUsers(ctx, ids)
+}
+
+// getUsers - resolve users by ids, returns ids only when users service circuit is open.
+func (s *ServiceImpl) getUsers(ctx context.Context, ids []string) ([]*User, error) {
+	usersResp, err := s.usersClient.GetUsers(ctx, &schema.GetUsersRequest{
+		Ids: ids,
+	})
+	if err != nil {
+		if errors.Is(err, usersclient.ErrCircuitOpen) {
+			return idsToUsers(ids), nil
+		}
+
+		return nil, err
+	}
+
+	return convertPBUsersToInner(usersResp.GetUsers()), nil
+}
+
+func idsToUsers(ids []string) []*User {
+	uu := make([]*User, 0, len(ids))
+
+	for i := range ids {
+		uu = append(uu, &User{
+			ID: ids[i],
+		})
+	}
+
+	return uu
 }
 
 func convertPBUsersToInner(uu []*schema.User) []*User {
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// GRPCConnChecker - check client connection is not failing, idle connection is asked to connect.
+func GRPCConnChecker(conn *grpc.ClientConn) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		state := conn.GetState()
+
+		switch state {
+		case connectivity.Ready, connectivity.Connecting:
+			return nil
+		case connectivity.Idle:
+			conn.Connect()
+
+			return nil
+		default:
+			return fmt.Errorf("connection state is %s", state)
+		}
+	})
+}
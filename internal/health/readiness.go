@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const checkTimeout = 2 * time.Second
+
+var errDraining = errors.New("server is shutting down")
+
+// Checker - dependency check.
+type Checker interface {
+	Check(context.Context) error
+}
+
+// CheckerFunc - function adapter for Checker.
+type CheckerFunc func(context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Result - single dependency check result.
+type Result struct {
+	Name string
+	Err  error
+}
+
+// Readiness - named dependency checks and shutdown draining state.
+type Readiness struct {
+	mu       sync.RWMutex
+	checkers map[string]Checker
+
+	draining   int32
+	drainDelay time.Duration
+}
+
+// NewReadiness - Readiness constructor, drainDelay is how long not-ready is reported before shutdown.
+func NewReadiness(drainDelay time.Duration) *Readiness {
+	return &Readiness{
+		checkers:   map[string]Checker{},
+		drainDelay: drainDelay,
+	}
+}
+
+// Add - register dependency check.
+func (r *Readiness) Add(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkers[name] = checker
+}
+
+// Drain - report not ready and wait for load balancers to notice.
+func (r *Readiness) Drain() {
+	atomic.StoreInt32(&r.draining, 1)
+	time.Sleep(r.drainDelay)
+}
+
+// Check - run every check concurrently, ready is false if draining or any check failed.
+func (r *Readiness) Check(ctx context.Context) (bool, []Result) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.checkers))
+	for name := range r.checkers {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+
+	sort.Strings(names)
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	results := make([]Result, len(names))
+	wg := &sync.WaitGroup{}
+
+	for i := range names {
+		r.mu.RLock()
+		checker := r.checkers[names[i]]
+		r.mu.RUnlock()
+
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+
+			results[i] = Result{
+				Name: names[i],
+				Err:  checker.Check(ctx),
+			}
+		}(i, checker)
+	}
+
+	wg.Wait()
+
+	ready := atomic.LoadInt32(&r.draining) == 0
+	if !ready {
+		results = append(results, Result{Name: "shutdown", Err: errDraining})
+	}
+
+	for i := range results {
+		if results[i].Err != nil {
+			ready = false
+		}
+	}
+
+	return ready, results
+}
@@ -0,0 +1,185 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/config"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const (
+	// SASLMechanismPlain - SASL/PLAIN.
+	SASLMechanismPlain = "PLAIN"
+	// SASLMechanismSCRAMSHA256 - SASL/SCRAM with sha256.
+	SASLMechanismSCRAMSHA256 = "SCRAM-SHA-256"
+	// SASLMechanismSCRAMSHA512 - SASL/SCRAM with sha512.
+	SASLMechanismSCRAMSHA512 = "SCRAM-SHA-512"
+
+	dialTimeout = 10 * time.Second
+)
+
+// Config - kafka connection config shared by consumers, tags are read by config.Load.
+type Config struct {
+	Brokers []string `config:"kafka.brokers" env:"KAFKA_BROKER" usage:"comma separated brokers"`
+	TLS     TLSConfig
+	SASL    SASLConfig
+}
+
+// ConsumerConfig - connection config with consumed topic and group.
+type ConsumerConfig struct {
+	Config
+	Topic   string `config:"kafka.topic" env:"KAFKA_TOPIC" usage:"users events topic"`
+	GroupID string `config:"kafka.group_id" env:"KAFKA_GROUP_ID"`
+}
+
+// TLSConfig - kafka TLS config.
+type TLSConfig struct {
+	Enabled            bool   `config:"kafka.tls.enabled" env:"KAFKA_TLS_ENABLED" default:"false"`
+	CAFile             string `config:"kafka.tls.ca_file" env:"KAFKA_TLS_CA_FILE"`
+	CertFile           string `config:"kafka.tls.cert_file" env:"KAFKA_TLS_CERT_FILE"`
+	KeyFile            string `config:"kafka.tls.key_file" env:"KAFKA_TLS_KEY_FILE"`
+	InsecureSkipVerify bool   `config:"kafka.tls.insecure_skip_verify" env:"KAFKA_TLS_INSECURE_SKIP_VERIFY" default:"false"`
+}
+
+// SASLConfig - kafka SASL config, empty mechanism disables SASL.
+type SASLConfig struct {
+	Mechanism string `config:"kafka.sasl.mechanism" env:"KAFKA_SASL_MECHANISM" usage:"PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512"`
+	Username  string `config:"kafka.sasl.username" env:"KAFKA_SASL_USERNAME"`
+	Password  string `config:"kafka.sasl.password" env:"KAFKA_SASL_PASSWORD" secret:"true"`
+}
+
+// Validate - check topic and group are set and connection config is consistent.
+func (c *ConsumerConfig) Validate() error {
+	errs := config.Errors{}
+	errs.Add(config.Require(c, "kafka.topic", "kafka.group_id"))
+	errs.Add(c.Config.Validate())
+
+	return errs.Err()
+}
+
+// Validate - check config consistency and that referenced files are readable.
+func (c *Config) Validate() error {
+	err := config.Require(c, "kafka.brokers")
+	if err != nil {
+		return err
+	}
+
+	if c.TLS.Enabled {
+		_, err = c.TLS.build()
+		if err != nil {
+			return err
+		}
+	} else if c.TLS.CAFile != "" || c.TLS.CertFile != "" || c.TLS.KeyFile != "" {
+		return errors.New("kafka: tls files are provided but tls is disabled")
+	}
+
+	if c.SASL.Mechanism != "" {
+		_, err = c.SASL.build()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Dialer - build kafka dialer for readers and consumer groups.
+func (c *Config) Dialer() (*kafka.Dialer, error) {
+	tlsCfg, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	mechanism, err := c.saslMechanism()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Dialer{
+		Timeout:       dialTimeout,
+		DualStack:     true,
+		TLS:           tlsCfg,
+		SASLMechanism: mechanism,
+	}, nil
+}
+
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	if !c.TLS.Enabled {
+		return nil, nil
+	}
+
+	return c.TLS.build()
+}
+
+func (c *Config) saslMechanism() (sasl.Mechanism, error) {
+	if c.SASL.Mechanism == "" {
+		return nil, nil
+	}
+
+	return c.SASL.build()
+}
+
+func (t *TLSConfig) build() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CAFile != "" {
+		bb, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("kafka: read tls ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bb) {
+			return nil, fmt.Errorf("kafka: no certificates found in %s", t.CAFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, errors.New("kafka: tls cert and key files must be provided together")
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("kafka: load tls client certificate: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+func (s *SASLConfig) build() (sasl.Mechanism, error) {
+	if s.Username == "" || s.Password == "" {
+		return nil, errors.New("kafka: sasl username and password must be provided")
+	}
+
+	switch strings.ToUpper(s.Mechanism) {
+	case SASLMechanismPlain:
+		return plain.Mechanism{
+			Username: s.Username,
+			Password: s.Password,
+		}, nil
+	case SASLMechanismSCRAMSHA256:
+		return scram.Mechanism(scram.SHA256, s.Username, s.Password)
+	case SASLMechanismSCRAMSHA512:
+		return scram.Mechanism(scram.SHA512, s.Username, s.Password)
+	default:
+		return nil, fmt.Errorf("kafka: unsupported sasl mechanism %q", s.Mechanism)
+	}
+}
@@ -3,54 +3,119 @@ package kafka
 import (
 	"context"
 	"encoding/json"
-	"time"
+	"errors"
+	"fmt"
 
 	"github.com/segmentio/kafka-go"
 )
 
+// ErrMalformedMessage - message was fetched but its value is not valid JSON of expected type,
+// skipping it by commit is safe unlike other UnmarshalMessage errors.
+var ErrMalformedMessage = errors.New("malformed message")
+
+// Headers - kafka message headers.
+type Headers map[string]string
+
+func (h Headers) Get(key string) string {
+	return h[key]
+}
+
+func (h Headers) Set(key string, value string) {
+	h[key] = value
+}
+
+func (h Headers) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
 // Consumer - kafka consumer.
 type Consumer interface {
-	UnmarshalMessage(context.Context, interface{}) (CommitFunc, error)
+	UnmarshalMessage(context.Context, interface{}) (CommitFunc, Headers, error)
+	// Lag - messages left behind the last fetched one.
+	Lag() int64
 	Close() error
 }
 
 // ConsumerImpl - consumer implementation.
 type ConsumerImpl struct {
-	reader *kafka.Reader
+	reader  *kafka.Reader
+	dialer  *kafka.Dialer
+	brokers []string
 }
 
 // NewComcumerImpl - ConsumerImpl constructor.
-func NewConsumerImpl(topic string, brokers []string, groupID string) *ConsumerImpl {
+func NewConsumerImpl(topic string, groupID string, cfg *Config) (*ConsumerImpl, error) {
+	dialer, err := cfg.Dialer()
+	if err != nil {
+		return nil, err
+	}
+
 	return &ConsumerImpl{
 		reader: kafka.NewReader(kafka.ReaderConfig{
-			Brokers: brokers,
+			Brokers: cfg.Brokers,
 			Topic:   topic,
 			GroupID: groupID,
-			Dialer: &kafka.Dialer{
-				Timeout:   10 * time.Second,
-				DualStack: true,
-			},
+			Dialer:  dialer,
 		}),
-	}
+		dialer:  dialer,
+		brokers: cfg.Brokers,
+	}, nil
 }
 
 // UnmarshalMessage - fetch message from kafka broker.
-func (c *ConsumerImpl) UnmarshalMessage(ctx context.Context, msg interface{}) (CommitFunc, error) {
+func (c *ConsumerImpl) UnmarshalMessage(ctx context.Context, msg interface{}) (CommitFunc, Headers, error) {
 	kafkaMsg, err := c.reader.FetchMessage(ctx)
 	if err != nil {
-		return func(ctx2 context.Context) error { return nil }, err
+		return func(ctx2 context.Context) error { return nil }, Headers{}, err
 	}
 
+	headers := convertHeaders(kafkaMsg.Headers)
+
 	err = json.Unmarshal(kafkaMsg.Value, &msg)
 	if err != nil {
-		return func(ctx2 context.Context) error { return nil }, err
+		return func(ctx2 context.Context) error { return nil }, headers, fmt.Errorf("%w: %s", ErrMalformedMessage, err)
 	}
 
 	return func(ctx2 context.Context) error {
 		return c.reader.CommitMessages(ctx2, kafkaMsg)
-	}, nil
+	}, headers, nil
+}
+
+// Ping - check that at least one broker accepts connections.
+func (c *ConsumerImpl) Ping(ctx context.Context) error {
+	var err error
+
+	for _, broker := range c.brokers {
+		var conn *kafka.Conn
+
+		conn, err = c.dialer.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn.Close()
+		}
+	}
+
+	return err
+}
+
+func (c *ConsumerImpl) Lag() int64 {
+	return c.reader.Stats().Lag
 }
 
 func (c *ConsumerImpl) Close() error {
 	return c.reader.Close()
 }
+
+func convertHeaders(hh []kafka.Header) Headers {
+	headers := make(Headers, len(hh))
+
+	for i := range hh {
+		headers[hh[i].Key] = string(hh[i].Value)
+	}
+
+	return headers
+}
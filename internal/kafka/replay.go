@@ -0,0 +1,272 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var _ Consumer = (*ReplayConsumerImpl)(nil)
+
+// replayIdleTimeout - fetch wait after which partition is considered read. Offsets before
+// the end observed at start are not always delivered, e.g. transaction markers or compacted records.
+const replayIdleTimeout = 15 * time.Second
+
+var (
+	// ErrReplayDone - every partition was read up to the offsets observed at replay start.
+	ErrReplayDone = errors.New("replay done")
+
+	errPartitionIdle = errors.New("partition idle")
+)
+
+// ReplayStart - replay starting position.
+// Offset is used when it is not negative, otherwise Time is used when it is not zero,
+// otherwise replay resumes from the replay group committed offsets.
+type ReplayStart struct {
+	Offset int64
+	Time   time.Time
+}
+
+type replayPartition struct {
+	id    int
+	start int64
+	end   int64
+}
+
+// ReplayConsumerImpl - consumer that reads topic history up to the current end
+// and commits progress to its own consumer group.
+type ReplayConsumerImpl struct {
+	topic   string
+	brokers []string
+	dialer  *kafka.Dialer
+
+	group      *kafka.ConsumerGroup
+	generation *kafka.Generation
+
+	partitions []*replayPartition
+	current    *replayPartition
+	reader     *kafka.Reader
+}
+
+// NewReplayConsumerImpl - ReplayConsumerImpl constructor.
+func NewReplayConsumerImpl(ctx context.Context, topic string, groupID string, cfg *Config, start ReplayStart) (*ReplayConsumerImpl, error) {
+	dialer, err := cfg.Dialer()
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := kafka.NewConsumerGroup(kafka.ConsumerGroupConfig{
+		ID:          groupID,
+		Brokers:     cfg.Brokers,
+		Dialer:      dialer,
+		Topics:      []string{topic},
+		StartOffset: kafka.FirstOffset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create consumer group: %w", err)
+	}
+
+	generation, err := group.Next(ctx)
+	if err != nil {
+		group.Close()
+
+		return nil, fmt.Errorf("join consumer group: %w", err)
+	}
+
+	c := &ReplayConsumerImpl{
+		topic:      topic,
+		brokers:    cfg.Brokers,
+		dialer:     dialer,
+		group:      group,
+		generation: generation,
+	}
+
+	for _, assignment := range generation.Assignments[topic] {
+		p, err := c.resolvePartition(ctx, assignment, start)
+		if err != nil {
+			group.Close()
+
+			return nil, err
+		}
+
+		if p.start >= p.end {
+			continue
+		}
+
+		c.partitions = append(c.partitions, p)
+	}
+
+	return c, nil
+}
+
+// Lag - number of messages left to replay.
+func (c *ReplayConsumerImpl) Lag() int64 {
+	var pending int64
+
+	if c.current != nil {
+		pending += c.current.end - c.current.start
+	}
+
+	for _, p := range c.partitions {
+		pending += p.end - p.start
+	}
+
+	return pending
+}
+
+// UnmarshalMessage - fetch next message, returns ErrReplayDone when replay is over.
+// Commit is returned with ErrMalformedMessage only, other errors leave nothing to commit.
+func (c *ReplayConsumerImpl) UnmarshalMessage(ctx context.Context, msg interface{}) (CommitFunc, Headers, error) {
+	noopCommit := func(ctx2 context.Context) error { return nil }
+
+	var (
+		p        *replayPartition
+		kafkaMsg kafka.Message
+		err      error
+	)
+
+	for {
+		if c.current == nil {
+			err = c.nextPartition()
+			if err != nil {
+				return noopCommit, Headers{}, err
+			}
+		}
+
+		p = c.current
+
+		kafkaMsg, err = c.fetch(ctx)
+		if errors.Is(err, errPartitionIdle) {
+			c.closeReader()
+
+			continue
+		}
+
+		if err != nil {
+			return noopCommit, Headers{}, err
+		}
+
+		break
+	}
+
+	p.start = kafkaMsg.Offset + 1
+	if p.start >= p.end {
+		c.closeReader()
+	}
+
+	commit := func(ctx2 context.Context) error {
+		return c.generation.CommitOffsets(map[string]map[int]int64{
+			c.topic: {p.id: kafkaMsg.Offset + 1},
+		})
+	}
+
+	headers := convertHeaders(kafkaMsg.Headers)
+
+	err = json.Unmarshal(kafkaMsg.Value, &msg)
+	if err != nil {
+		return commit, headers, fmt.Errorf("%w: %s", ErrMalformedMessage, err)
+	}
+
+	return commit, headers, nil
+}
+
+// fetch - fetch message of current partition, errPartitionIdle means nothing is left to deliver before its end.
+func (c *ReplayConsumerImpl) fetch(ctx context.Context) (kafka.Message, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, replayIdleTimeout)
+	defer cancel()
+
+	kafkaMsg, err := c.reader.FetchMessage(fetchCtx)
+	if err == nil || ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
+		return kafkaMsg, err
+	}
+
+	// reader retries broker errors silently, idle partition is finished only when its leader answers
+	_, err = c.reader.ReadLag(ctx)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("read partition %d lag: %w", c.current.id, err)
+	}
+
+	return kafka.Message{}, errPartitionIdle
+}
+
+func (c *ReplayConsumerImpl) Close() error {
+	c.closeReader()
+
+	return c.group.Close()
+}
+
+func (c *ReplayConsumerImpl) resolvePartition(ctx context.Context, assignment kafka.PartitionAssignment, start ReplayStart) (*replayPartition, error) {
+	conn, err := c.dialer.DialLeader(ctx, "tcp", c.brokers[0], c.topic, assignment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("dial partition %d leader: %w", assignment.ID, err)
+	}
+	defer conn.Close()
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return nil, fmt.Errorf("read partition %d offsets: %w", assignment.ID, err)
+	}
+
+	p := &replayPartition{
+		id:    assignment.ID,
+		start: first,
+		end:   last,
+	}
+
+	switch {
+	case start.Offset >= 0:
+		if start.Offset > first {
+			p.start = start.Offset
+		}
+	case !start.Time.IsZero():
+		p.start, err = conn.ReadOffset(start.Time)
+		if err != nil {
+			return nil, fmt.Errorf("read partition %d offset at %s: %w", assignment.ID, start.Time, err)
+		}
+	case assignment.Offset >= first:
+		p.start = assignment.Offset
+	}
+
+	return p, nil
+}
+
+func (c *ReplayConsumerImpl) nextPartition() error {
+	if len(c.partitions) == 0 {
+		return ErrReplayDone
+	}
+
+	p := c.partitions[0]
+	c.partitions = c.partitions[1:]
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   c.brokers,
+		Topic:     c.topic,
+		Partition: p.id,
+		Dialer:    c.dialer,
+	})
+
+	err := reader.SetOffset(p.start)
+	if err != nil {
+		reader.Close()
+
+		return fmt.Errorf("set partition %d offset: %w", p.id, err)
+	}
+
+	c.current = p
+	c.reader = reader
+
+	return nil
+}
+
+func (c *ReplayConsumerImpl) closeReader() {
+	if c.reader != nil {
+		c.reader.Close()
+	}
+
+	c.current = nil
+	c.reader = nil
+}
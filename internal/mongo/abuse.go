@@ -0,0 +1,89 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AbuseSignals - per user counters used to spot request spam.
+type AbuseSignals struct {
+	UID              string    `bson:"uid"`
+	RequestsSent     int64     `bson:"requests_sent"`
+	RequestsAccepted int64     `bson:"requests_accepted"`
+	RequestsDeclined int64     `bson:"requests_declined"`
+	RateLimited      int64     `bson:"rate_limited"`
+	HighDeclineRatio bool      `bson:"high_decline_ratio"`
+	FlaggedAt        time.Time `bson:"flagged_at,omitempty"`
+	UpdatedAt        time.Time `bson:"updated_at"`
+}
+
+// AbuseSignalsDelta - abuse counters increments.
+type AbuseSignalsDelta struct {
+	RequestsSent     int64
+	RequestsAccepted int64
+	RequestsDeclined int64
+	RateLimited      int64
+}
+
+// DeclineRatio - share of sent requests that were declined.
+func (a *AbuseSignals) DeclineRatio() float64 {
+	if a.RequestsSent == 0 {
+		return 0
+	}
+
+	return float64(a.RequestsDeclined) / float64(a.RequestsSent)
+}
+
+func (a AbuseSignalsDelta) toBSOND() bson.D {
+	return bson.D{
+		{Key: "requests_sent", Value: a.RequestsSent},
+		{Key: "requests_accepted", Value: a.RequestsAccepted},
+		{Key: "requests_declined", Value: a.RequestsDeclined},
+		{Key: "rate_limited", Value: a.RateLimited},
+	}
+}
+
+func (d *DBImpl) IncAbuseSignals(ctx context.Context, uid string, delta AbuseSignalsDelta) (*AbuseSignals, error) {
+	ctx, end := startOperation(ctx, "inc_abuse_signals")
+	defer end()
+
+	filter := bson.D{{Key: "uid", Value: uid}}
+	update := bson.D{
+		{Key: "$inc", Value: delta.toBSOND()},
+		{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now().UTC()}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	signals := &AbuseSignals{}
+
+	err := d.abuseSignalsCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(signals)
+	if err != nil {
+		return nil, err
+	}
+
+	return signals, nil
+}
+
+func (d *DBImpl) FlagHighDeclineRatio(ctx context.Context, uid string) error {
+	ctx, end := startOperation(ctx, "flag_high_decline_ratio")
+	defer end()
+
+	now := time.Now().UTC()
+
+	filter := bson.D{
+		{Key: "uid", Value: uid},
+		{Key: "high_decline_ratio", Value: bson.D{{Key: "$ne", Value: true}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "high_decline_ratio", Value: true},
+		{Key: "flagged_at", Value: now},
+		{Key: "updated_at", Value: now},
+	}}}
+
+	_, err := d.abuseSignalsCollection.UpdateOne(ctx, filter, update)
+
+	return err
+}
@@ -0,0 +1,178 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/daniilty/sharenote-friends/internal/audit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Edge - directed graph edge, friendships are stored as two edges.
+type Edge struct {
+	From string
+	To   string
+}
+
+// ForceAddFriend - make users friends without pending request,
+// drops pending requests between them.
+func (d *DBImpl) ForceAddFriend(ctx context.Context, uid string, friendID string) error {
+	return d.withTransaction(ctx, "force_add_friend", d.getMakeFriendsTransaction(uid, friendID, audit.ActionFriendForceAdded))
+}
+
+// ForceRemoveFriend - remove friendship edges in both directions, even if only one exists.
+func (d *DBImpl) ForceRemoveFriend(ctx context.Context, uid string, friendID string) error {
+	return d.withTransaction(ctx, "force_remove_friend", d.getForceRemoveFriendTransaction(uid, friendID))
+}
+
+// RemoveFriendRequest - drop pending request sent by from to to.
+func (d *DBImpl) RemoveFriendRequest(ctx context.Context, from string, to string) error {
+	return d.withTransaction(ctx, "remove_friend_request", func(sessCtx mongo.SessionContext) (interface{}, error) {
+		err := d.pullID(sessCtx, d.friendRequestsCollection, to, from, false)
+		if err != nil {
+			return nil, fmt.Errorf("remove request from %s: %w", from, err)
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionFriendRequestRemoved, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
+		return nil, nil
+	})
+}
+
+// ForEachFriendEdge - call fn for every friend edge.
+func (d *DBImpl) ForEachFriendEdge(ctx context.Context, fn func(Edge) error) error {
+	ctx, end := startOperation(ctx, "for_each_friend_edge")
+	defer end()
+
+	return forEachEdge(ctx, d.friendsCollection, fn)
+}
+
+// ForEachFriendRequestEdge - call fn for every pending request edge, From is sender.
+func (d *DBImpl) ForEachFriendRequestEdge(ctx context.Context, fn func(Edge) error) error {
+	ctx, end := startOperation(ctx, "for_each_friend_request_edge")
+	defer end()
+
+	return forEachEdge(ctx, d.friendRequestsCollection, func(e Edge) error {
+		// requests are stored on recipient document
+		return fn(Edge{From: e.To, To: e.From})
+	})
+}
+
+func forEachEdge(ctx context.Context, collection *mongo.Collection, fn func(Edge) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "uid", Value: 1}})
+
+	cursor, err := collection.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		doc := &Friends{}
+
+		err = cursor.Decode(doc)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range doc.FriendIDs {
+			err = fn(Edge{From: doc.UID, To: id})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return cursor.Err()
+}
+
+// pullID - remove id and its timestamp from uid document,
+// friend favorite mark and annotation are dropped too.
+func (d *DBImpl) pullID(ctx context.Context, collection *mongo.Collection, uid string, id string, upsert bool) error {
+	pull := bson.D{{Key: "friend_ids", Value: id}}
+	unset := bson.D{{Key: d.timesField(collection) + "." + id, Value: ""}}
+
+	if collection == d.friendsCollection {
+		pull = append(pull, bson.E{Key: "favorite_ids", Value: id})
+		unset = append(unset, bson.E{Key: "annotations." + id, Value: ""})
+	}
+
+	update := bson.D{
+		{Key: "$pull", Value: pull},
+		{Key: "$unset", Value: unset},
+	}
+
+	had, err := d.updateID(ctx, collection, uid, id, update, upsert)
+	if err != nil || !had {
+		return err
+	}
+
+	return d.countEdge(ctx, collection, uid, id, -1)
+}
+
+// addID - add id to friend_ids of uid document, creating it if needed,
+// timestamp of already present id is kept.
+func (d *DBImpl) addID(ctx context.Context, collection *mongo.Collection, uid string, id string) error {
+	update := bson.D{
+		{Key: "$addToSet", Value: bson.D{{Key: "friend_ids", Value: id}}},
+		{Key: "$min", Value: bson.D{{Key: d.timesField(collection) + "." + id, Value: now()}}},
+	}
+
+	had, err := d.updateID(ctx, collection, uid, id, update, true)
+	if err != nil || had {
+		return err
+	}
+
+	return d.countEdge(ctx, collection, uid, id, 1)
+}
+
+// updateID - apply update to uid document, reports whether id was in friend_ids before it.
+func (d *DBImpl) updateID(ctx context.Context, collection *mongo.Collection, uid string, id string, update bson.D, upsert bool) (bool, error) {
+	filter := bson.D{{Key: "uid", Value: uid}}
+	// only id itself is returned when it is present
+	projection := bson.D{
+		{Key: "_id", Value: 0},
+		{Key: "friend_ids", Value: bson.D{{Key: "$elemMatch", Value: bson.D{{Key: "$eq", Value: id}}}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(upsert).SetProjection(projection)
+
+	before := &Friends{}
+
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return len(before.FriendIDs) > 0, nil
+}
+
+func (d *DBImpl) getForceRemoveFriendTransaction(uid string, friendID string) transactionFunc {
+	return func(sessCtx mongo.SessionContext) (interface{}, error) {
+		err := d.pullID(sessCtx, d.friendsCollection, uid, friendID, false)
+		if err != nil {
+			return nil, fmt.Errorf("remove friend from %s: %w", uid, err)
+		}
+
+		err = d.pullID(sessCtx, d.friendsCollection, friendID, uid, false)
+		if err != nil {
+			return nil, fmt.Errorf("remove friend from %s: %w", friendID, err)
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionFriendForceRemoved, uid, friendID)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
+		return nil, nil
+	}
+}
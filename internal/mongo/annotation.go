@@ -0,0 +1,44 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Annotation - private nickname and note user attached to friend.
+type Annotation struct {
+	Nickname  string    `bson:"nickname,omitempty"`
+	Note      string    `bson:"note,omitempty"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+// SetAnnotation - set friend annotation, nil annotation removes it.
+// Ok is true when users are not friends.
+func (d *DBImpl) SetAnnotation(ctx context.Context, uid string, friendID string, a *Annotation) (bool, error) {
+	ctx, end := startOperation(ctx, "set_annotation")
+	defer end()
+
+	field := "annotations." + friendID
+
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: field, Value: ""}}}}
+	if a != nil {
+		a.UpdatedAt = now()
+		update = bson.D{{Key: "$set", Value: bson.D{{Key: field, Value: a}}}}
+	}
+
+	// friendship is checked by the same single document update, so it cannot end in between
+	filter := bson.D{{Key: "uid", Value: uid}, {Key: "friend_ids", Value: friendID}}
+
+	res, err := d.friendsCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	if res.MatchedCount == 0 {
+		return true, errNotFriends
+	}
+
+	return true, nil
+}
@@ -0,0 +1,87 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/audit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditEntry - friendship graph change record, entries are never updated.
+type AuditEntry struct {
+	Actor  string    `bson:"actor"`
+	Source string    `bson:"source"`
+	Action string    `bson:"action"`
+	UID    string    `bson:"uid"`
+	Target string    `bson:"target,omitempty"`
+	At     time.Time `bson:"at"`
+}
+
+// AuditFilter - audit query, zero time bounds are open.
+type AuditFilter struct {
+	// UID - user that is either subject or target of change.
+	UID   string
+	From  time.Time
+	To    time.Time
+	Limit int64
+}
+
+// writeAudit - append audit entry, actor is taken from context.
+// Must be called with transaction session context to be atomic with change.
+func (d *DBImpl) writeAudit(ctx context.Context, action string, uid string, target string) error {
+	actor, _ := audit.ActorFromContext(ctx)
+
+	_, err := d.auditCollection.InsertOne(ctx, &AuditEntry{
+		Actor:  actor.ID,
+		Source: actor.Source,
+		Action: action,
+		UID:    uid,
+		Target: target,
+		At:     now(),
+	})
+
+	return err
+}
+
+// GetAuditEntries - get newest first entries involving user.
+func (d *DBImpl) GetAuditEntries(ctx context.Context, f AuditFilter) ([]*AuditEntry, error) {
+	ctx, end := startOperation(ctx, "get_audit_entries")
+	defer end()
+
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "uid", Value: f.UID}},
+		bson.D{{Key: "target", Value: f.UID}},
+	}}}
+
+	at := bson.D{}
+	if !f.From.IsZero() {
+		at = append(at, bson.E{Key: "$gte", Value: f.From})
+	}
+
+	if !f.To.IsZero() {
+		at = append(at, bson.E{Key: "$lt", Value: f.To})
+	}
+
+	if len(at) > 0 {
+		filter = append(filter, bson.E{Key: "at", Value: at})
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "at", Value: -1}}).SetLimit(f.Limit)
+
+	cursor, err := d.auditCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []*AuditEntry{}
+
+	err = cursor.All(ctx, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
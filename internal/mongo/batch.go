@@ -0,0 +1,73 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RequestOutcome - result of handling one friend request of batch.
+type RequestOutcome int
+
+const (
+	// OutcomeDone - request was accepted or declined.
+	OutcomeDone RequestOutcome = iota
+	// OutcomeNotFound - there is no pending request from user.
+	OutcomeNotFound
+	// OutcomeAlreadyFriends - users are friends already, stale request is dropped.
+	OutcomeAlreadyFriends
+	// OutcomeLimitExceeded - accepting would take one of users over friends limit.
+	OutcomeLimitExceeded
+)
+
+// AcceptFriendRequests - accept requests sent to uid by ids in one transaction,
+// outcomes are returned in ids order.
+func (d *DBImpl) AcceptFriendRequests(ctx context.Context, uid string, ids []string) ([]RequestOutcome, error) {
+	return d.handleFriendRequests(ctx, "accept_friend_requests", ids, func(id string) transactionFunc {
+		return d.getAddFriendTransaction(id, uid)
+	})
+}
+
+// DeclineFriendRequests - decline requests sent to uid by ids in one transaction,
+// outcomes are returned in ids order.
+func (d *DBImpl) DeclineFriendRequests(ctx context.Context, uid string, ids []string) ([]RequestOutcome, error) {
+	return d.handleFriendRequests(ctx, "decline_friend_requests", ids, func(id string) transactionFunc {
+		return d.getDeclineFriendRequestTransaction(id, uid)
+	})
+}
+
+// handleFriendRequests - run single request transactions inside one transaction,
+// client errors become outcomes and do not abort the rest.
+func (d *DBImpl) handleFriendRequests(ctx context.Context, name string, ids []string, handle func(string) transactionFunc) ([]RequestOutcome, error) {
+	var outcomes []RequestOutcome
+
+	err := d.withTransaction(ctx, name, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// transaction may be retried, outcomes of aborted attempt are dropped
+		outcomes = make([]RequestOutcome, 0, len(ids))
+
+		for _, id := range ids {
+			_, err := handle(id)(sessCtx)
+
+			switch {
+			case err == nil:
+				outcomes = append(outcomes, OutcomeDone)
+			case errors.Is(err, errNotInFriendRequests):
+				outcomes = append(outcomes, OutcomeNotFound)
+			case errors.Is(err, errAlreadyFriends):
+				outcomes = append(outcomes, OutcomeAlreadyFriends)
+			case isLimitError(err):
+				outcomes = append(outcomes, OutcomeLimitExceeded)
+			default:
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return outcomes, nil
+}
@@ -0,0 +1,214 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/audit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Block - user blocked by other user, blocked users cannot follow, request,
+// befriend or be routed through each other in either direction.
+type Block struct {
+	Blocker   string    `bson:"blocker"`
+	Blocked   string    `bson:"blocked"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// Block - make transaction, add block and drop friendship, requests and follows between users.
+func (d *DBImpl) Block(ctx context.Context, from string, to string) (bool, error) {
+	err := d.withTransaction(ctx, "block", d.getBlockTransaction(from, to))
+	if err != nil {
+		if errors.Is(err, errAlreadyBlocked) {
+			return true, err
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Unblock - make transaction and remove block, dropped relations are not restored.
+func (d *DBImpl) Unblock(ctx context.Context, from string, to string) (bool, error) {
+	err := d.withTransaction(ctx, "unblock", d.getUnblockTransaction(from, to))
+	if err != nil {
+		if errors.Is(err, errNotBlocked) {
+			return true, err
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetBlocks - get newest first users blocked by user.
+func (d *DBImpl) GetBlocks(ctx context.Context, uid string) ([]*Block, error) {
+	ctx, end := startOperation(ctx, "get_blocks")
+	defer end()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetProjection(bson.D{{Key: "_id", Value: 0}})
+
+	cursor, err := d.blocksCollection.Find(ctx, bson.D{{Key: "blocker", Value: uid}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	blocks := []*Block{}
+
+	err = cursor.All(ctx, &blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// GetBlockedIDs - ids of users blocked by or blocking user.
+func (d *DBImpl) GetBlockedIDs(ctx context.Context, uid string) ([]string, error) {
+	ctx, end := startOperation(ctx, "get_blocked_ids")
+	defer end()
+
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "blocker", Value: uid}},
+		bson.D{{Key: "blocked", Value: uid}},
+	}}}
+
+	cursor, err := d.blocksCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ids := []string{}
+
+	for cursor.Next(ctx) {
+		b := &Block{}
+
+		err = cursor.Decode(b)
+		if err != nil {
+			return nil, err
+		}
+
+		if b.Blocker == uid {
+			ids = append(ids, b.Blocked)
+		} else {
+			ids = append(ids, b.Blocker)
+		}
+	}
+
+	return ids, cursor.Err()
+}
+
+// DeleteUserBlocks - delete blocks made by and of user.
+func (d *DBImpl) DeleteUserBlocks(ctx context.Context, uid string) error {
+	ctx, end := startOperation(ctx, "delete_user_blocks")
+	defer end()
+
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "blocker", Value: uid}},
+		bson.D{{Key: "blocked", Value: uid}},
+	}}}
+
+	_, err := d.blocksCollection.DeleteMany(ctx, filter)
+
+	return err
+}
+
+// checkNotBlocked - errBlocked when either user blocked the other one.
+func (d *DBImpl) checkNotBlocked(ctx context.Context, uid string, otherID string) error {
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "blocker", Value: uid}, {Key: "blocked", Value: otherID}},
+		bson.D{{Key: "blocker", Value: otherID}, {Key: "blocked", Value: uid}},
+	}}}
+
+	n, err := d.blocksCollection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return fmt.Errorf("check blocks: %w", err)
+	}
+
+	if n > 0 {
+		return errBlocked
+	}
+
+	return nil
+}
+
+func (d *DBImpl) getBlockTransaction(from string, to string) transactionFunc {
+	return func(sessCtx mongo.SessionContext) (interface{}, error) {
+		_, err := d.blocksCollection.InsertOne(sessCtx, &Block{
+			Blocker:   from,
+			Blocked:   to,
+			CreatedAt: now(),
+		})
+		if err != nil {
+			// unique blocker, blocked index
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, errAlreadyBlocked
+			}
+
+			return nil, fmt.Errorf("insert block: %w", err)
+		}
+
+		// counters are updated by pullID
+		for _, edge := range []Edge{{From: from, To: to}, {From: to, To: from}} {
+			err = d.pullID(sessCtx, d.friendsCollection, edge.From, edge.To, false)
+			if err != nil {
+				return nil, fmt.Errorf("remove friend from %s: %w", edge.From, err)
+			}
+
+			err = d.pullID(sessCtx, d.friendRequestsCollection, edge.From, edge.To, false)
+			if err != nil {
+				return nil, fmt.Errorf("remove request from %s: %w", edge.To, err)
+			}
+		}
+
+		filter := bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "follower", Value: from}, {Key: "followee", Value: to}},
+			bson.D{{Key: "follower", Value: to}, {Key: "followee", Value: from}},
+		}}}
+
+		_, err = d.followsCollection.DeleteMany(sessCtx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("delete follows: %w", err)
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionBlocked, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+func (d *DBImpl) getUnblockTransaction(from string, to string) transactionFunc {
+	return func(sessCtx mongo.SessionContext) (interface{}, error) {
+		filter := bson.D{{Key: "blocker", Value: from}, {Key: "blocked", Value: to}}
+
+		res, err := d.blocksCollection.DeleteOne(sessCtx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("delete block: %w", err)
+		}
+
+		if res.DeletedCount == 0 {
+			return nil, errNotBlocked
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionUnblocked, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
+		return nil, nil
+	}
+}
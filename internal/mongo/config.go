@@ -0,0 +1,17 @@
+package mongo
+
+import (
+	"github.com/daniilty/sharenote-friends/internal/config"
+)
+
+// Config - mongo connection config shared by server and friendsctl, tags are read by config.Load.
+type Config struct {
+	ConnString  string `config:"mongo.conn_string" env:"MONGO_CONN_STRING" secret:"true" usage:"required"`
+	DBName      string `config:"mongo.db_name" env:"MONGO_DB_NAME" usage:"required"`
+	Collections CollectionNames
+}
+
+// Validate - check values without defaults are set.
+func (c *Config) Validate() error {
+	return config.Require(c, "mongo.conn_string", "mongo.db_name", "mongo.friends_collection", "mongo.friend_requests_collection")
+}
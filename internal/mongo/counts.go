@@ -0,0 +1,369 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Counts - denormalised number of user friends and pending requests,
+// kept in sync by every transaction changing them.
+type Counts struct {
+	UID             string `bson:"uid"`
+	Friends         int64  `bson:"friends"`
+	IncomingPending int64  `bson:"incoming_pending"`
+	OutgoingPending int64  `bson:"outgoing_pending"`
+}
+
+// CountsDelta - counters increments.
+type CountsDelta struct {
+	Friends         int64
+	IncomingPending int64
+	OutgoingPending int64
+}
+
+// CountsRecompute - result of counters recomputation.
+type CountsRecompute struct {
+	Users int
+	// Fixed - users whose stored counters differed from friends and requests.
+	Fixed []*CountsFix
+}
+
+// CountsFix - stored and recomputed user counters.
+type CountsFix struct {
+	Stored Counts
+	Actual Counts
+}
+
+func (c CountsDelta) toBSOND() bson.D {
+	return bson.D{
+		{Key: "friends", Value: c.Friends},
+		{Key: "incoming_pending", Value: c.IncomingPending},
+		{Key: "outgoing_pending", Value: c.OutgoingPending},
+	}
+}
+
+// GetCounts - get counters of users in uids order, users without counters have zero ones.
+func (d *DBImpl) GetCounts(ctx context.Context, uids []string) ([]*Counts, error) {
+	ctx, end := startOperation(ctx, "get_counts")
+	defer end()
+
+	filter := bson.D{{Key: "uid", Value: bson.D{{Key: "$in", Value: uids}}}}
+
+	cursor, err := d.countsCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	byUID := make(map[string]*Counts, len(uids))
+
+	for cursor.Next(ctx) {
+		c := &Counts{}
+
+		err = cursor.Decode(c)
+		if err != nil {
+			return nil, err
+		}
+
+		byUID[c.UID] = c
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]*Counts, 0, len(uids))
+
+	for _, uid := range uids {
+		c, ok := byUID[uid]
+		if !ok {
+			c = &Counts{UID: uid}
+		}
+
+		counts = append(counts, c)
+	}
+
+	return counts, nil
+}
+
+// RecomputeCounts - rebuild counters of every user from friends and requests,
+// only reports mismatches unless apply is set. Outgoing requests of all users
+// are counted by one aggregation, then each user is compared in own transaction,
+// so concurrent changes are not lost.
+func (d *DBImpl) RecomputeCounts(ctx context.Context, apply bool) (*CountsRecompute, error) {
+	ctx, end := startOperation(ctx, "recompute_counts")
+	defer end()
+
+	outgoing, err := d.countOutgoingRequests(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("count outgoing requests: %w", err)
+	}
+
+	uids, err := d.getCountedUIDs(ctx, outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &CountsRecompute{
+		Users: len(uids),
+	}
+
+	for _, uid := range uids {
+		var fix *CountsFix
+
+		err = d.withTransaction(ctx, "recompute_user_counts", func(sessCtx mongo.SessionContext) (interface{}, error) {
+			var err error
+
+			fix, err = d.recomputeUserCounts(sessCtx, uid, outgoing[uid], apply)
+
+			return nil, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("recompute %s counts: %w", uid, err)
+		}
+
+		if fix != nil {
+			res.Fixed = append(res.Fixed, fix)
+		}
+	}
+
+	return res, nil
+}
+
+// countOutgoingRequests - number of pending requests sent by each user.
+func (d *DBImpl) countOutgoingRequests(ctx context.Context) (map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$unwind", Value: "$friend_ids"}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$friend_ids"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+
+	cursor, err := d.friendRequestsCollection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	outgoing := map[string]int64{}
+
+	for cursor.Next(ctx) {
+		var group struct {
+			UID   string `bson:"_id"`
+			Count int64  `bson:"count"`
+		}
+
+		err = cursor.Decode(&group)
+		if err != nil {
+			return nil, err
+		}
+
+		outgoing[group.UID] = group.Count
+	}
+
+	return outgoing, cursor.Err()
+}
+
+// getCountedUIDs - ids of users having friends, requests, outgoing requests or stored counters.
+func (d *DBImpl) getCountedUIDs(ctx context.Context, outgoing map[string]int64) ([]string, error) {
+	seen := map[string]bool{}
+	uids := []string{}
+
+	for uid := range outgoing {
+		seen[uid] = true
+		uids = append(uids, uid)
+	}
+
+	collect := func(collection *mongo.Collection, fields ...string) error {
+		for _, field := range fields {
+			values, err := collection.Distinct(ctx, field, bson.D{})
+			if err != nil {
+				return fmt.Errorf("distinct %s %s: %w", collection.Name(), field, err)
+			}
+
+			for _, v := range values {
+				uid, ok := v.(string)
+				if ok && !seen[uid] {
+					seen[uid] = true
+					uids = append(uids, uid)
+				}
+			}
+		}
+
+		return nil
+	}
+
+	err := collect(d.friendsCollection, "uid")
+	if err != nil {
+		return nil, err
+	}
+
+	err = collect(d.friendRequestsCollection, "uid")
+	if err != nil {
+		return nil, err
+	}
+
+	err = collect(d.countsCollection, "uid")
+	if err != nil {
+		return nil, err
+	}
+
+	return uids, nil
+}
+
+// recomputeUserCounts - count user friends and requests, returns nil when stored counters match.
+// Aggregated outgoing count may be stale, so it is counted again inside transaction on mismatch.
+func (d *DBImpl) recomputeUserCounts(sessCtx mongo.SessionContext, uid string, outgoing int64, apply bool) (*CountsFix, error) {
+	stored, err := d.GetCounts(sessCtx, []string{uid})
+	if err != nil {
+		return nil, fmt.Errorf("get counts: %w", err)
+	}
+
+	friends, err := d.GetFriends(sessCtx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get friends: %w", err)
+	}
+
+	requests, err := d.GetFriendRequests(sessCtx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get friend requests: %w", err)
+	}
+
+	actual := Counts{
+		UID:             uid,
+		Friends:         int64(len(friends.FriendIDs)),
+		IncomingPending: int64(len(requests.FriendIDs)),
+		OutgoingPending: outgoing,
+	}
+
+	if *stored[0] == actual {
+		return nil, nil
+	}
+
+	actual.OutgoingPending, err = d.friendRequestsCollection.CountDocuments(sessCtx, bson.D{{Key: "friend_ids", Value: uid}})
+	if err != nil {
+		return nil, fmt.Errorf("count outgoing requests: %w", err)
+	}
+
+	if *stored[0] == actual {
+		return nil, nil
+	}
+
+	if apply {
+		filter := bson.D{{Key: "uid", Value: uid}}
+
+		_, err = d.countsCollection.ReplaceOne(sessCtx, filter, actual, options.Replace().SetUpsert(true))
+		if err != nil {
+			return nil, fmt.Errorf("replace counts: %w", err)
+		}
+	}
+
+	return &CountsFix{Stored: *stored[0], Actual: actual}, nil
+}
+
+// incCounts - add delta to user counters.
+func (d *DBImpl) incCounts(ctx context.Context, uid string, delta CountsDelta) error {
+	filter := bson.D{{Key: "uid", Value: uid}}
+	update := bson.D{{Key: "$inc", Value: delta.toBSOND()}}
+
+	_, err := d.countsCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+
+	return err
+}
+
+// countEdge - update counters after id was added to or removed from uid document of collection,
+// sign is 1 for added and -1 for removed id. Request edge also changes sender outgoing counter.
+func (d *DBImpl) countEdge(ctx context.Context, collection *mongo.Collection, uid string, id string, sign int64) error {
+	if collection == d.friendsCollection {
+		return d.incCounts(ctx, uid, CountsDelta{Friends: sign})
+	}
+
+	err := d.incCounts(ctx, uid, CountsDelta{IncomingPending: sign})
+	if err != nil {
+		return err
+	}
+
+	return d.incCounts(ctx, id, CountsDelta{OutgoingPending: sign})
+}
+
+// countFriendship - update friends counters of both users after they became friends or strangers.
+func (d *DBImpl) countFriendship(ctx context.Context, uid string, friendID string, sign int64) error {
+	err := d.countEdge(ctx, d.friendsCollection, uid, friendID, sign)
+	if err != nil {
+		return err
+	}
+
+	return d.countEdge(ctx, d.friendsCollection, friendID, uid, sign)
+}
+
+// countUserRemoval - update counters of users having removed user in friends or requests,
+// must run before user is pulled from their documents.
+func (d *DBImpl) countUserRemoval(ctx context.Context, uid string) error {
+	friendOf, err := d.getUIDsContaining(ctx, d.friendsCollection, uid)
+	if err != nil {
+		return fmt.Errorf("get users having friend: %w", err)
+	}
+
+	for _, id := range friendOf {
+		err = d.incCounts(ctx, id, CountsDelta{Friends: -1})
+		if err != nil {
+			return err
+		}
+	}
+
+	requested, err := d.getUIDsContaining(ctx, d.friendRequestsCollection, uid)
+	if err != nil {
+		return fmt.Errorf("get users having request: %w", err)
+	}
+
+	for _, id := range requested {
+		err = d.incCounts(ctx, id, CountsDelta{IncomingPending: -1})
+		if err != nil {
+			return err
+		}
+	}
+
+	requests, err := d.GetFriendRequests(ctx, uid)
+	if err != nil {
+		return fmt.Errorf("get friend requests: %w", err)
+	}
+
+	for _, id := range requests.FriendIDs {
+		err = d.incCounts(ctx, id, CountsDelta{OutgoingPending: -1})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = d.countsCollection.DeleteOne(ctx, bson.D{{Key: "uid", Value: uid}})
+
+	return err
+}
+
+// getUIDsContaining - uids of collection documents having id in friend_ids.
+func (d *DBImpl) getUIDsContaining(ctx context.Context, collection *mongo.Collection, id string) ([]string, error) {
+	values, err := collection.Distinct(ctx, "uid", bson.D{{Key: "friend_ids", Value: id}})
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make([]string, 0, len(values))
+
+	for _, v := range values {
+		uid, ok := v.(string)
+		if !ok {
+			return nil, errors.New("uid is not a string")
+		}
+
+		uids = append(uids, uid)
+	}
+
+	return uids, nil
+}
@@ -3,10 +3,12 @@ package mongo
 import (
 	"context"
 	"log"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 var _ DB = (*DBImpl)(nil)
@@ -14,34 +16,153 @@ var _ DB = (*DBImpl)(nil)
 type DB interface {
 	// GetNote - get user by id.
 	GetFriendRequests(context.Context, string) (*FriendRequests, error)
+	// GetOutgoingFriendRequests - get uids of users that have pending request from user.
+	GetOutgoingFriendRequests(context.Context, string) ([]string, error)
+	// GetOutgoingRequestedAt - get creation time of pending requests sent by user.
+	GetOutgoingRequestedAt(context.Context, string) (map[string]time.Time, error)
 	// UpdateFriendRequests - update or insert friend requests for user.
 	UpdateFriendRequests(context.Context, *FriendRequests) error
+	// RequestFriend - make transaction and add request from user to other user requests.
+	RequestFriend(context.Context, string, string) (bool, error)
+	// DeclineFriendRequest - make transaction and drop request from user.
+	DeclineFriendRequest(context.Context, string, string) (bool, error)
+	// AcceptFriendRequests - accept requests from several users in one transaction.
+	AcceptFriendRequests(context.Context, string, []string) ([]RequestOutcome, error)
+	// DeclineFriendRequests - decline requests from several users in one transaction.
+	DeclineFriendRequests(context.Context, string, []string) ([]RequestOutcome, error)
 	// RemoveUser - remove user's requests and friends.
 	RemoveUser(context.Context, string) error
+	// GetUserReferences - count documents RemoveUser would change.
+	GetUserReferences(context.Context, string) (*UserReferences, error)
 	// GetFriends - get user friends.
 	GetFriends(context.Context, string) (*Friends, error)
+	// GetFriendIDs - get friend ids of several users.
+	GetFriendIDs(context.Context, []string) (map[string][]string, error)
 	// AddFriend - make transaction and add user to friends list.
 	AddFriend(context.Context, string, string) (bool, error)
 	// RemoveFriend - make transaction and remove friend from each other list.
 	RemoveFriend(context.Context, string, string) (bool, error)
+	// SetFavorite - mark or unmark friend as favorite.
+	SetFavorite(context.Context, string, string, bool) (bool, error)
+	// SetAnnotation - set or remove friend private nickname and note.
+	SetAnnotation(context.Context, string, string, *Annotation) (bool, error)
 	// UpdateFriends - update user friends.
 	UpdateFriends(context.Context, *Friends) error
+	// ForceAddFriend - make users friends without pending request.
+	ForceAddFriend(context.Context, string, string) error
+	// ForceRemoveFriend - remove friendship edges in both directions.
+	ForceRemoveFriend(context.Context, string, string) error
+	// RemoveFriendRequest - drop pending request.
+	RemoveFriendRequest(context.Context, string, string) error
+	// ForEachFriendEdge - iterate friend edges.
+	ForEachFriendEdge(context.Context, func(Edge) error) error
+	// ForEachFriendRequestEdge - iterate pending request edges.
+	ForEachFriendRequestEdge(context.Context, func(Edge) error) error
+	// BackfillTimestamps - set UnknownTime for ids stored without timestamp.
+	BackfillTimestamps(context.Context, bool) ([]*TimestampsBackfill, error)
+	// InsertInvite - store new invite.
+	InsertInvite(context.Context, *Invite) error
+	// GetInvites - get active invites issued by user.
+	GetInvites(context.Context, string) ([]*Invite, error)
+	// RevokeInvite - delete invite issued by user.
+	RevokeInvite(context.Context, string, string) (bool, error)
+	// RedeemInvite - make transaction and apply invite for user.
+	RedeemInvite(context.Context, string, string) (*Invite, bool, error)
+	// Follow - make transaction and add follow edge.
+	Follow(context.Context, string, string) (bool, error)
+	// Unfollow - make transaction and remove follow edge.
+	Unfollow(context.Context, string, string) (bool, error)
+	// RemoveFollower - make transaction and remove follow edge to user.
+	RemoveFollower(context.Context, string, string) (bool, error)
+	// GetFollows - get page of users followed by or following user.
+	GetFollows(context.Context, FollowFilter) ([]*Follow, error)
+	// ForEachFollows - iterate all follow edges of user in chunks.
+	ForEachFollows(context.Context, FollowFilter, int, func([]*Follow) error) error
+	// Block - make transaction, block user and drop relations with them.
+	Block(context.Context, string, string) (bool, error)
+	// Unblock - make transaction and unblock user.
+	Unblock(context.Context, string, string) (bool, error)
+	// GetBlocks - get users blocked by user.
+	GetBlocks(context.Context, string) ([]*Block, error)
+	// GetBlockedIDs - get ids of users blocked by or blocking user.
+	GetBlockedIDs(context.Context, string) ([]string, error)
+	// GetSettings - get user privacy settings.
+	GetSettings(context.Context, string) (*Settings, error)
+	// SetSettings - replace user privacy settings.
+	SetSettings(context.Context, *Settings) error
+	// GetHiddenConnectionsIDs - get ids of users hiding their connections.
+	GetHiddenConnectionsIDs(context.Context, []string) ([]string, error)
+	// GetLimits - get user friends and pending requests limits.
+	GetLimits(context.Context, string) (Limits, error)
+	// GetLimitsOverride - get user limits override.
+	GetLimitsOverride(context.Context, string) (*LimitsOverride, error)
+	// SetLimitsOverride - replace user limits override.
+	SetLimitsOverride(context.Context, *LimitsOverride) error
+	// GetCounts - get users friends and pending requests counters.
+	GetCounts(context.Context, []string) ([]*Counts, error)
+	// RecomputeCounts - rebuild counters from friends and requests.
+	RecomputeCounts(context.Context, bool) (*CountsRecompute, error)
+	// GetAuditEntries - get changes involving user.
+	GetAuditEntries(context.Context, AuditFilter) ([]*AuditEntry, error)
+	// IncAbuseSignals - add delta to user abuse counters, returns updated counters.
+	IncAbuseSignals(context.Context, string, AbuseSignalsDelta) (*AbuseSignals, error)
+	// FlagHighDeclineRatio - mark user as having high sent requests decline ratio.
+	FlagHighDeclineRatio(context.Context, string) error
+}
+
+// CollectionNames - names of service collections, tags are read by config.Load.
+type CollectionNames struct {
+	Friends        string `config:"mongo.friends_collection" env:"MONGO_FRIENDS_COLLECTION_NAME" usage:"required"`
+	FriendRequests string `config:"mongo.friend_requests_collection" env:"MONGO_FRIEND_REQUESTS_COLLECTION_NAME" usage:"required"`
+	AbuseSignals   string `config:"mongo.abuse_signals_collection" env:"MONGO_ABUSE_SIGNALS_COLLECTION_NAME" default:"friend_abuse_signals"`
+	Audit          string `config:"mongo.audit_collection" env:"MONGO_AUDIT_COLLECTION_NAME" default:"friend_audit"`
+	Invites        string `config:"mongo.invites_collection" env:"MONGO_INVITES_COLLECTION_NAME" default:"friend_invites"`
+	Follows        string `config:"mongo.follows_collection" env:"MONGO_FOLLOWS_COLLECTION_NAME" default:"friend_follows"`
+	Limits         string `config:"mongo.limits_collection" env:"MONGO_LIMITS_COLLECTION_NAME" default:"friend_limits"`
+	Counts         string `config:"mongo.counts_collection" env:"MONGO_COUNTS_COLLECTION_NAME" default:"friend_counts"`
+	Blocks         string `config:"mongo.blocks_collection" env:"MONGO_BLOCKS_COLLECTION_NAME" default:"friend_blocks"`
+	Settings       string `config:"mongo.settings_collection" env:"MONGO_SETTINGS_COLLECTION_NAME" default:"friend_settings"`
 }
 
 type DBImpl struct {
 	mongoDB                  *mongo.Database
 	friendRequestsCollection *mongo.Collection
 	friendsCollection        *mongo.Collection
+	abuseSignalsCollection   *mongo.Collection
+	auditCollection          *mongo.Collection
+	invitesCollection        *mongo.Collection
+	followsCollection        *mongo.Collection
+	limitsCollection         *mongo.Collection
+	countsCollection         *mongo.Collection
+	blocksCollection         *mongo.Collection
+	settingsCollection       *mongo.Collection
+
+	limits Limits
 }
 
-func NewDBImpl(db *mongo.Database, friendRequestsCollection *mongo.Collection, friendsCollection *mongo.Collection) *DBImpl {
+// NewDBImpl - DBImpl constructor, limits are defaults enforced by friendship transactions.
+func NewDBImpl(db *mongo.Database, names CollectionNames, limits Limits) *DBImpl {
 	return &DBImpl{
 		mongoDB:                  db,
-		friendsCollection:        friendsCollection,
-		friendRequestsCollection: friendRequestsCollection,
+		friendsCollection:        db.Collection(names.Friends),
+		friendRequestsCollection: db.Collection(names.FriendRequests),
+		abuseSignalsCollection:   db.Collection(names.AbuseSignals),
+		auditCollection:          db.Collection(names.Audit),
+		invitesCollection:        db.Collection(names.Invites),
+		followsCollection:        db.Collection(names.Follows),
+		limitsCollection:         db.Collection(names.Limits),
+		countsCollection:         db.Collection(names.Counts),
+		blocksCollection:         db.Collection(names.Blocks),
+		settingsCollection:       db.Collection(names.Settings),
+		limits:                   limits,
 	}
 }
 
+// Ping - check primary is reachable.
+func (d *DBImpl) Ping(ctx context.Context) error {
+	return d.mongoDB.Client().Ping(ctx, readpref.Primary())
+}
+
 func Connect(ctx context.Context, addr string) (*mongo.Client, error) {
 	return mongo.Connect(ctx, options.Client().ApplyURI(addr))
 }
@@ -3,7 +3,16 @@ package mongo
 import "errors"
 
 var (
-	errNotInFriendRequests = errors.New("not in friend requests")
+	errNotInFriendRequests = errors.New("user is not in friend requests")
+	errAlreadyRequested    = errors.New("user is already in friend requests")
 	errAlreadyFriends      = errors.New("users are already friends")
 	errNotFriends          = errors.New("users are not friends")
+	errAlreadyFollowing    = errors.New("user is already followed")
+	errNotFollowing        = errors.New("user is not followed")
+	errNotFollower         = errors.New("user does not follow you")
+	errAlreadyBlocked      = errors.New("user is already blocked")
+	errNotBlocked          = errors.New("user is not blocked")
+	errBlocked             = errors.New("user is blocked")
+	errInviteNotFound      = errors.New("invite is expired, revoked or already used")
+	errSelfInvite          = errors.New("you cannot redeem your own invite")
 )
@@ -0,0 +1,33 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SetFavorite - mark or unmark friend as favorite, ok is true when users are not friends.
+func (d *DBImpl) SetFavorite(ctx context.Context, uid string, friendID string, favorite bool) (bool, error) {
+	ctx, end := startOperation(ctx, "set_favorite")
+	defer end()
+
+	op := "$pull"
+	if favorite {
+		op = "$addToSet"
+	}
+
+	// friendship is checked by the same single document update, so it cannot end in between
+	filter := bson.D{{Key: "uid", Value: uid}, {Key: "friend_ids", Value: friendID}}
+	update := bson.D{{Key: op, Value: bson.D{{Key: "favorite_ids", Value: friendID}}}}
+
+	res, err := d.friendsCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	if res.MatchedCount == 0 {
+		return true, errNotFriends
+	}
+
+	return true, nil
+}
@@ -0,0 +1,257 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/audit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Follow - one-way follow edge, unlike friendship it needs no approval.
+type Follow struct {
+	Follower  string    `bson:"follower"`
+	Followee  string    `bson:"followee"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// FollowFilter - follows query, newest first, zero Before is open.
+type FollowFilter struct {
+	UID string
+	// Followers - list users following UID instead of users followed by UID.
+	Followers bool
+	Before    time.Time
+	Limit     int64
+}
+
+// Follow - make transaction and add follow edge.
+func (d *DBImpl) Follow(ctx context.Context, from string, to string) (bool, error) {
+	err := d.withTransaction(ctx, "follow", d.getFollowTransaction(from, to))
+	if err != nil {
+		if errors.Is(err, errAlreadyFollowing) || errors.Is(err, errBlocked) {
+			return true, err
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Unfollow - make transaction and remove follow edge.
+func (d *DBImpl) Unfollow(ctx context.Context, from string, to string) (bool, error) {
+	err := d.withTransaction(ctx, "unfollow", d.getUnfollowTransaction(from, to))
+	if err != nil {
+		if errors.Is(err, errNotFollowing) {
+			return true, err
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RemoveFollower - make transaction and remove follow edge from follower to user.
+func (d *DBImpl) RemoveFollower(ctx context.Context, uid string, follower string) (bool, error) {
+	err := d.withTransaction(ctx, "remove_follower", d.getRemoveFollowerTransaction(uid, follower))
+	if err != nil {
+		if errors.Is(err, errNotFollower) {
+			return true, err
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetFollows - get page of follow edges of user, limit is required.
+func (d *DBImpl) GetFollows(ctx context.Context, f FollowFilter) ([]*Follow, error) {
+	ctx, end := startOperation(ctx, "get_follows")
+	defer end()
+
+	if f.Limit <= 0 {
+		return nil, errors.New("follows limit must be positive")
+	}
+
+	cursor, err := d.findFollows(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	follows := []*Follow{}
+
+	err = cursor.All(ctx, &follows)
+	if err != nil {
+		return nil, err
+	}
+
+	return follows, nil
+}
+
+// ForEachFollows - call fn for every follow edge of user in chunks of at most size edges,
+// Before and Limit of filter are ignored.
+func (d *DBImpl) ForEachFollows(ctx context.Context, f FollowFilter, size int, fn func([]*Follow) error) error {
+	ctx, end := startOperation(ctx, "for_each_follows")
+	defer end()
+
+	f.Before = time.Time{}
+	f.Limit = 0
+
+	cursor, err := d.findFollows(ctx, f, options.Find().SetBatchSize(int32(size)))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	chunk := make([]*Follow, 0, size)
+
+	for cursor.Next(ctx) {
+		follow := &Follow{}
+
+		err = cursor.Decode(follow)
+		if err != nil {
+			return err
+		}
+
+		chunk = append(chunk, follow)
+
+		if len(chunk) == size {
+			err = fn(chunk)
+			if err != nil {
+				return err
+			}
+
+			chunk = make([]*Follow, 0, size)
+		}
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		return err
+	}
+
+	if len(chunk) == 0 {
+		return nil
+	}
+
+	return fn(chunk)
+}
+
+func (d *DBImpl) findFollows(ctx context.Context, f FollowFilter, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	field := "follower"
+	if f.Followers {
+		field = "followee"
+	}
+
+	filter := bson.D{{Key: field, Value: f.UID}}
+
+	if !f.Before.IsZero() {
+		filter = append(filter, bson.E{Key: "created_at", Value: bson.D{{Key: "$lt", Value: f.Before}}})
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetProjection(bson.D{{Key: "_id", Value: 0}})
+
+	if f.Limit > 0 {
+		findOpts.SetLimit(f.Limit)
+	}
+
+	return d.followsCollection.Find(ctx, filter, append([]*options.FindOptions{findOpts}, opts...)...)
+}
+
+// DeleteUserFollows - delete follow edges from and to user.
+func (d *DBImpl) DeleteUserFollows(ctx context.Context, uid string) error {
+	ctx, end := startOperation(ctx, "delete_user_follows")
+	defer end()
+
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "follower", Value: uid}},
+		bson.D{{Key: "followee", Value: uid}},
+	}}}
+
+	_, err := d.followsCollection.DeleteMany(ctx, filter)
+
+	return err
+}
+
+func (d *DBImpl) getFollowTransaction(from string, to string) transactionFunc {
+	return func(sessCtx mongo.SessionContext) (interface{}, error) {
+		err := d.checkNotBlocked(sessCtx, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = d.followsCollection.InsertOne(sessCtx, &Follow{
+			Follower:  from,
+			Followee:  to,
+			CreatedAt: now(),
+		})
+		if err != nil {
+			// unique follower, followee index
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, errAlreadyFollowing
+			}
+
+			return nil, fmt.Errorf("insert follow: %w", err)
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionFollowed, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+func (d *DBImpl) getUnfollowTransaction(from string, to string) transactionFunc {
+	return func(sessCtx mongo.SessionContext) (interface{}, error) {
+		filter := bson.D{{Key: "follower", Value: from}, {Key: "followee", Value: to}}
+
+		res, err := d.followsCollection.DeleteOne(sessCtx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("delete follow: %w", err)
+		}
+
+		if res.DeletedCount == 0 {
+			return nil, errNotFollowing
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionUnfollowed, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+func (d *DBImpl) getRemoveFollowerTransaction(uid string, follower string) transactionFunc {
+	return func(sessCtx mongo.SessionContext) (interface{}, error) {
+		filter := bson.D{{Key: "follower", Value: follower}, {Key: "followee", Value: uid}}
+
+		res, err := d.followsCollection.DeleteOne(sessCtx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("delete follow: %w", err)
+		}
+
+		if res.DeletedCount == 0 {
+			return nil, errNotFollower
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionFollowerRemoved, uid, follower)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
+		return nil, nil
+	}
+}
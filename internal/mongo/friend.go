@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/daniilty/sharenote-friends/internal/audit"
 	"github.com/daniilty/sharenote-friends/internal/slice"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -15,29 +17,99 @@ type FriendRequests struct {
 	ID        string   `bson:"_id"`
 	UID       string   `bson:"uid"`
 	FriendIDs []string `bson:"friend_ids"`
+	// RequestedAt - request creation time by sender id.
+	RequestedAt map[string]time.Time `bson:"requested_at,omitempty"`
 }
 
 type Friends struct {
 	ID        string   `bson:"_id"`
 	UID       string   `bson:"uid"`
 	FriendIDs []string `bson:"friend_ids"`
+	// Since - friendship creation time by friend id.
+	Since map[string]time.Time `bson:"since,omitempty"`
+	// FavoriteIDs - friends marked as close by user, subset of FriendIDs.
+	FavoriteIDs []string `bson:"favorite_ids,omitempty"`
+	// Annotations - private nickname and note by friend id.
+	Annotations map[string]*Annotation `bson:"annotations,omitempty"`
 }
 
 func (f *FriendRequests) toBSOND() bson.D {
-	return bson.D{
+	d := bson.D{
 		{Key: "uid", Value: f.UID},
 		{Key: "friend_ids", Value: f.FriendIDs},
 	}
+
+	if f.RequestedAt != nil {
+		d = append(d, bson.E{Key: "requested_at", Value: f.RequestedAt})
+	}
+
+	return d
 }
 
 func (f *Friends) toBSOND() bson.D {
-	return bson.D{
+	d := bson.D{
 		{Key: "uid", Value: f.UID},
 		{Key: "friend_ids", Value: f.FriendIDs},
 	}
+
+	if f.Since != nil {
+		d = append(d, bson.E{Key: "since", Value: f.Since})
+	}
+
+	if f.FavoriteIDs != nil {
+		d = append(d, bson.E{Key: "favorite_ids", Value: f.FavoriteIDs})
+	}
+
+	if f.Annotations != nil {
+		d = append(d, bson.E{Key: "annotations", Value: f.Annotations})
+	}
+
+	return d
+}
+
+// add - add request from id created at given time.
+func (f *FriendRequests) add(id string, at time.Time) {
+	f.FriendIDs = append(f.FriendIDs, id)
+
+	if f.RequestedAt == nil {
+		f.RequestedAt = map[string]time.Time{}
+	}
+
+	f.RequestedAt[id] = at
+}
+
+// remove - drop request from id.
+func (f *FriendRequests) remove(id string) {
+	f.FriendIDs = slice.RemoveString(f.FriendIDs, id)
+	delete(f.RequestedAt, id)
+}
+
+// add - add friend id made friends at given time.
+func (f *Friends) add(id string, at time.Time) {
+	f.FriendIDs = append(f.FriendIDs, id)
+
+	if f.Since == nil {
+		f.Since = map[string]time.Time{}
+	}
+
+	f.Since[id] = at
+}
+
+// remove - drop friend id with its favorite mark and annotation.
+func (f *Friends) remove(id string) {
+	f.FriendIDs = slice.RemoveString(f.FriendIDs, id)
+	delete(f.Since, id)
+	delete(f.Annotations, id)
+
+	if f.FavoriteIDs != nil {
+		f.FavoriteIDs = slice.RemoveString(f.FavoriteIDs, id)
+	}
 }
 
 func (d *DBImpl) GetFriendRequests(ctx context.Context, uid string) (*FriendRequests, error) {
+	ctx, end := startOperation(ctx, "get_friend_requests")
+	defer end()
+
 	filter := bson.D{{Key: "uid", Value: uid}}
 
 	res := d.friendRequestsCollection.FindOne(ctx, filter)
@@ -55,7 +127,38 @@ func (d *DBImpl) GetFriendRequests(ctx context.Context, uid string) (*FriendRequ
 	return fr, nil
 }
 
+func (d *DBImpl) GetOutgoingFriendRequests(ctx context.Context, uid string) ([]string, error) {
+	ctx, end := startOperation(ctx, "get_outgoing_friend_requests")
+	defer end()
+
+	filter := bson.M{"friend_ids": uid}
+	opts := options.Find().SetProjection(bson.M{"uid": 1})
+
+	cursor, err := d.friendRequestsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	uids := []string{}
+
+	for cursor.Next(ctx) {
+		fr := &FriendRequests{}
+
+		err = cursor.Decode(fr)
+		if err != nil {
+			return nil, err
+		}
+
+		uids = append(uids, fr.UID)
+	}
+
+	return uids, cursor.Err()
+}
+
 func (d *DBImpl) UpdateFriendRequests(ctx context.Context, fr *FriendRequests) error {
+	ctx, end := startOperation(ctx, "update_friend_requests")
+	defer end()
 
 	filter := bson.D{{Key: "uid", Value: fr.UID}}
 	update := bson.D{{Key: "$set", Value: fr.toBSOND()}}
@@ -67,6 +170,9 @@ func (d *DBImpl) UpdateFriendRequests(ctx context.Context, fr *FriendRequests) e
 }
 
 func (d *DBImpl) GetFriends(ctx context.Context, uid string) (*Friends, error) {
+	ctx, end := startOperation(ctx, "get_friends")
+	defer end()
+
 	filter := bson.D{{Key: "uid", Value: uid}}
 
 	res := d.friendsCollection.FindOne(ctx, filter)
@@ -84,18 +190,40 @@ func (d *DBImpl) GetFriends(ctx context.Context, uid string) (*Friends, error) {
 	return f, nil
 }
 
-func (d *DBImpl) AddFriend(ctx context.Context, from string, to string) (bool, error) {
-	session, err := d.mongoDB.Client().StartSession()
+// GetFriendIDs - get friend ids of several users by user id, users without friends are missing.
+func (d *DBImpl) GetFriendIDs(ctx context.Context, uids []string) (map[string][]string, error) {
+	ctx, end := startOperation(ctx, "get_friend_ids")
+	defer end()
+
+	filter := bson.D{{Key: "uid", Value: bson.D{{Key: "$in", Value: uids}}}}
+	opts := options.Find().SetProjection(bson.M{"_id": 0, "uid": 1, "friend_ids": 1})
+
+	cursor, err := d.friendsCollection.Find(ctx, filter, opts)
 	if err != nil {
-		return false, fmt.Errorf("failed to start session: %w", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ids := make(map[string][]string, len(uids))
+
+	for cursor.Next(ctx) {
+		f := &Friends{}
+
+		err = cursor.Decode(f)
+		if err != nil {
+			return nil, err
+		}
+
+		ids[f.UID] = f.FriendIDs
 	}
-	defer session.EndSession(ctx)
 
-	transaction := d.getAddFriendTransaction(from, to)
+	return ids, cursor.Err()
+}
 
-	_, err = session.WithTransaction(ctx, transaction)
+func (d *DBImpl) AddFriend(ctx context.Context, from string, to string) (bool, error) {
+	err := d.withTransaction(ctx, "add_friend", d.getAddFriendTransaction(from, to))
 	if err != nil {
-		if errors.Is(err, errNotInFriendRequests) || errors.Is(err, errAlreadyFriends) {
+		if errors.Is(err, errNotInFriendRequests) || errors.Is(err, errAlreadyFriends) || isLimitError(err) {
 			return true, err
 		}
 
@@ -105,30 +233,38 @@ func (d *DBImpl) AddFriend(ctx context.Context, from string, to string) (bool, e
 	return true, nil
 }
 
-func (d *DBImpl) RemoveUser(ctx context.Context, uid string) error {
-	session, err := d.mongoDB.Client().StartSession()
+func (d *DBImpl) RequestFriend(ctx context.Context, from string, to string) (bool, error) {
+	err := d.withTransaction(ctx, "request_friend", d.getRequestFriendTransaction(from, to))
 	if err != nil {
-		return fmt.Errorf("failed to start session: %w", err)
-	}
-	defer session.EndSession(ctx)
-
-	transaction := d.getRemoveUserTransaction(uid)
+		if errors.Is(err, errAlreadyRequested) || errors.Is(err, errBlocked) || isLimitError(err) {
+			return true, err
+		}
 
-	_, err = session.WithTransaction(ctx, transaction)
+		return false, err
+	}
 
-	return err
+	return true, nil
 }
 
-func (d *DBImpl) RemoveFriend(ctx context.Context, from string, to string) (bool, error) {
-	session, err := d.mongoDB.Client().StartSession()
+func (d *DBImpl) DeclineFriendRequest(ctx context.Context, from string, to string) (bool, error) {
+	err := d.withTransaction(ctx, "decline_friend_request", d.getDeclineFriendRequestTransaction(from, to))
 	if err != nil {
-		return false, fmt.Errorf("failed to start session: %w", err)
+		if errors.Is(err, errNotInFriendRequests) {
+			return true, err
+		}
+
+		return false, err
 	}
-	defer session.EndSession(ctx)
 
-	transaction := d.getRemoveFriendTransaction(from, to)
+	return true, nil
+}
 
-	_, err = session.WithTransaction(ctx, transaction)
+func (d *DBImpl) RemoveUser(ctx context.Context, uid string) error {
+	return d.withTransaction(ctx, "remove_user", d.getRemoveUserTransaction(uid))
+}
+
+func (d *DBImpl) RemoveFriend(ctx context.Context, from string, to string) (bool, error) {
+	err := d.withTransaction(ctx, "remove_friend", d.getRemoveFriendTransaction(from, to))
 	if err != nil {
 		if errors.Is(err, errNotFriends) {
 			return true, err
@@ -141,6 +277,9 @@ func (d *DBImpl) RemoveFriend(ctx context.Context, from string, to string) (bool
 }
 
 func (d *DBImpl) UpdateFriends(ctx context.Context, f *Friends) error {
+	ctx, end := startOperation(ctx, "update_friends")
+	defer end()
+
 	filter := bson.D{{Key: "uid", Value: f.UID}}
 	update := bson.D{{Key: "$set", Value: f.toBSOND()}}
 	opts := options.Update().SetUpsert(true)
@@ -151,6 +290,9 @@ func (d *DBImpl) UpdateFriends(ctx context.Context, f *Friends) error {
 }
 
 func (d *DBImpl) DeleteUsersFriends(ctx context.Context, uid string) error {
+	ctx, end := startOperation(ctx, "delete_users_friends")
+	defer end()
+
 	filter := bson.M{"uid": uid}
 
 	_, err := d.friendsCollection.DeleteOne(ctx, filter)
@@ -159,15 +301,24 @@ func (d *DBImpl) DeleteUsersFriends(ctx context.Context, uid string) error {
 }
 
 func (d *DBImpl) DeleteUserFromFriends(ctx context.Context, uid string) error {
-	filter := bson.M{}
-	update := bson.M{"$pull": bson.M{"friend_ids": uid}}
+	ctx, end := startOperation(ctx, "delete_user_from_friends")
+	defer end()
 
-	_, err := d.friendsCollection.UpdateOne(ctx, filter, update)
+	filter := bson.M{"friend_ids": uid}
+	update := bson.M{
+		"$pull":  bson.M{"friend_ids": uid, "favorite_ids": uid},
+		"$unset": bson.M{"since." + uid: "", "annotations." + uid: ""},
+	}
+
+	_, err := d.friendsCollection.UpdateMany(ctx, filter, update)
 
 	return err
 }
 
 func (d *DBImpl) DeleteUsersFriendRequests(ctx context.Context, uid string) error {
+	ctx, end := startOperation(ctx, "delete_users_friend_requests")
+	defer end()
+
 	filter := bson.M{"uid": uid}
 
 	_, err := d.friendRequestsCollection.DeleteOne(ctx, filter)
@@ -176,39 +327,108 @@ func (d *DBImpl) DeleteUsersFriendRequests(ctx context.Context, uid string) erro
 }
 
 func (d *DBImpl) DeleteUserFromFriendRequests(ctx context.Context, uid string) error {
-	filter := bson.M{}
-	update := bson.M{"$pull": bson.M{"friend_ids": uid}}
+	ctx, end := startOperation(ctx, "delete_user_from_friend_requests")
+	defer end()
 
-	_, err := d.friendRequestsCollection.UpdateOne(ctx, filter, update)
+	filter := bson.M{"friend_ids": uid}
+	update := bson.M{
+		"$pull":  bson.M{"friend_ids": uid},
+		"$unset": bson.M{"requested_at." + uid: ""},
+	}
+
+	_, err := d.friendRequestsCollection.UpdateMany(ctx, filter, update)
 
 	return err
 }
 
-func (d *DBImpl) getAddFriendTransaction(from string, to string) transactionFunc {
+func (d *DBImpl) getRequestFriendTransaction(from string, to string) transactionFunc {
 	return func(sessCtx mongo.SessionContext) (interface{}, error) {
 		requests, err := d.GetFriendRequests(sessCtx, to)
 		if err != nil {
-			return false, fmt.Errorf("get friend requests: %w", err)
+			return nil, fmt.Errorf("get friend requests: %w", err)
+		}
+
+		if slice.ContainsString(requests.FriendIDs, from) {
+			return nil, errAlreadyRequested
+		}
+
+		err = d.checkNotBlocked(sessCtx, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.checkRequestLimits(sessCtx, from, to, len(requests.FriendIDs))
+		if err != nil {
+			return nil, err
+		}
+
+		requests.add(from, now())
+
+		err = d.UpdateFriendRequests(sessCtx, requests)
+		if err != nil {
+			return nil, fmt.Errorf("update friend requests: %w", err)
+		}
+
+		err = d.countEdge(sessCtx, d.friendRequestsCollection, to, from, 1)
+		if err != nil {
+			return nil, fmt.Errorf("update counts: %w", err)
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionFriendRequested, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+func (d *DBImpl) getDeclineFriendRequestTransaction(from string, to string) transactionFunc {
+	return func(sessCtx mongo.SessionContext) (interface{}, error) {
+		requests, err := d.GetFriendRequests(sessCtx, to)
+		if err != nil {
+			return nil, fmt.Errorf("get friend requests: %w", err)
 		}
 
 		if !slice.ContainsString(requests.FriendIDs, from) {
-			return true, errNotInFriendRequests
+			return nil, errNotInFriendRequests
 		}
 
-		requests.FriendIDs = slice.RemoveString(requests.FriendIDs, from)
+		requests.remove(from)
 
 		err = d.UpdateFriendRequests(sessCtx, requests)
 		if err != nil {
 			return nil, fmt.Errorf("update friend requests: %w", err)
 		}
 
-		toFriends, err := d.GetFriends(sessCtx, to)
+		err = d.countEdge(sessCtx, d.friendRequestsCollection, to, from, -1)
 		if err != nil {
-			return nil, fmt.Errorf("get to friends: %w", err)
+			return nil, fmt.Errorf("update counts: %w", err)
 		}
 
-		if slice.ContainsString(toFriends.FriendIDs, from) {
-			return nil, errAlreadyFriends
+		err = d.writeAudit(sessCtx, audit.ActionFriendRequestDeclined, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+func (d *DBImpl) getAddFriendTransaction(from string, to string) transactionFunc {
+	return func(sessCtx mongo.SessionContext) (interface{}, error) {
+		requests, err := d.GetFriendRequests(sessCtx, to)
+		if err != nil {
+			return false, fmt.Errorf("get friend requests: %w", err)
+		}
+
+		if !slice.ContainsString(requests.FriendIDs, from) {
+			return true, errNotInFriendRequests
+		}
+
+		toFriends, err := d.GetFriends(sessCtx, to)
+		if err != nil {
+			return nil, fmt.Errorf("get to friends: %w", err)
 		}
 
 		fromFriends, err := d.GetFriends(sessCtx, from)
@@ -216,13 +436,41 @@ func (d *DBImpl) getAddFriendTransaction(from string, to string) transactionFunc
 			return nil, fmt.Errorf("get from friends: %w", err)
 		}
 
-		if slice.ContainsString(fromFriends.FriendIDs, to) {
+		alreadyFriends := slice.ContainsString(toFriends.FriendIDs, from) || slice.ContainsString(fromFriends.FriendIDs, to)
+
+		// limits are checked before any write, so batch keeps request that was not accepted
+		if !alreadyFriends {
+			err = d.checkFriendsLimit(sessCtx, to, len(toFriends.FriendIDs))
+			if err != nil {
+				return nil, err
+			}
+
+			err = d.checkFriendsLimit(sessCtx, from, len(fromFriends.FriendIDs))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		requests.remove(from)
+
+		err = d.UpdateFriendRequests(sessCtx, requests)
+		if err != nil {
+			return nil, fmt.Errorf("update friend requests: %w", err)
+		}
+
+		err = d.countEdge(sessCtx, d.friendRequestsCollection, to, from, -1)
+		if err != nil {
+			return nil, fmt.Errorf("update counts: %w", err)
+		}
+
+		if alreadyFriends {
 			return nil, errAlreadyFriends
 		}
 
 		// make friends with each other
-		toFriends.FriendIDs = append(toFriends.FriendIDs, from)
-		fromFriends.FriendIDs = append(fromFriends.FriendIDs, to)
+		since := now()
+		toFriends.add(from, since)
+		fromFriends.add(to, since)
 
 		err = d.UpdateFriends(sessCtx, toFriends)
 		if err != nil {
@@ -234,6 +482,49 @@ func (d *DBImpl) getAddFriendTransaction(from string, to string) transactionFunc
 			return nil, fmt.Errorf("update from friends: %w", err)
 		}
 
+		err = d.countFriendship(sessCtx, from, to, 1)
+		if err != nil {
+			return nil, fmt.Errorf("update counts: %w", err)
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionFriendAdded, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+// getMakeFriendsTransaction - make users friends without pending request,
+// requests between them are dropped and action is audited.
+func (d *DBImpl) getMakeFriendsTransaction(uid string, friendID string, action string) transactionFunc {
+	return func(sessCtx mongo.SessionContext) (interface{}, error) {
+		err := d.pullID(sessCtx, d.friendRequestsCollection, uid, friendID, false)
+		if err != nil {
+			return nil, fmt.Errorf("remove request from %s: %w", friendID, err)
+		}
+
+		err = d.pullID(sessCtx, d.friendRequestsCollection, friendID, uid, false)
+		if err != nil {
+			return nil, fmt.Errorf("remove request from %s: %w", uid, err)
+		}
+
+		err = d.addID(sessCtx, d.friendsCollection, uid, friendID)
+		if err != nil {
+			return nil, fmt.Errorf("add friend to %s: %w", uid, err)
+		}
+
+		err = d.addID(sessCtx, d.friendsCollection, friendID, uid)
+		if err != nil {
+			return nil, fmt.Errorf("add friend to %s: %w", friendID, err)
+		}
+
+		err = d.writeAudit(sessCtx, action, uid, friendID)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
 		return nil, nil
 	}
 }
@@ -259,8 +550,8 @@ func (d *DBImpl) getRemoveFriendTransaction(from string, to string) transactionF
 		}
 
 		// remove friends from each other
-		toFriends.FriendIDs = slice.RemoveString(toFriends.FriendIDs, from)
-		fromFriends.FriendIDs = slice.RemoveString(toFriends.FriendIDs, to)
+		toFriends.remove(from)
+		fromFriends.remove(to)
 
 		err = d.UpdateFriends(sessCtx, toFriends)
 		if err != nil {
@@ -272,13 +563,28 @@ func (d *DBImpl) getRemoveFriendTransaction(from string, to string) transactionF
 			return nil, fmt.Errorf("update from friends: %w", err)
 		}
 
+		err = d.countFriendship(sessCtx, from, to, -1)
+		if err != nil {
+			return nil, fmt.Errorf("update counts: %w", err)
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionFriendRemoved, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
 		return nil, nil
 	}
 }
 
 func (d *DBImpl) getRemoveUserTransaction(uid string) transactionFunc {
 	return func(sessCtx mongo.SessionContext) (interface{}, error) {
-		err := d.DeleteUserFromFriendRequests(sessCtx, uid)
+		err := d.countUserRemoval(sessCtx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("update counts: %w", err)
+		}
+
+		err = d.DeleteUserFromFriendRequests(sessCtx, uid)
 		if err != nil {
 			return nil, fmt.Errorf("delete user from friend requests: %w", err)
 		}
@@ -298,6 +604,36 @@ func (d *DBImpl) getRemoveUserTransaction(uid string) transactionFunc {
 			return nil, fmt.Errorf("delete users friends: %w", err)
 		}
 
+		err = d.DeleteUserFollows(sessCtx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("delete user follows: %w", err)
+		}
+
+		err = d.DeleteUserBlocks(sessCtx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("delete user blocks: %w", err)
+		}
+
+		err = d.DeleteSettings(sessCtx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("delete settings: %w", err)
+		}
+
+		err = d.DeleteLimitsOverride(sessCtx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("delete limits override: %w", err)
+		}
+
+		err = d.DeleteUsersInvites(sessCtx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("delete users invites: %w", err)
+		}
+
+		err = d.writeAudit(sessCtx, audit.ActionUserRemoved, uid, "")
+		if err != nil {
+			return nil, fmt.Errorf("write audit: %w", err)
+		}
+
 		return nil, nil
 	}
 }
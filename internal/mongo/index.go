@@ -0,0 +1,99 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes - create indexes of collections owned by service, existing ones are kept.
+func (d *DBImpl) EnsureIndexes(ctx context.Context) error {
+	indexes := []struct {
+		collection *mongo.Collection
+		models     []mongo.IndexModel
+	}{
+		{
+			collection: d.friendsCollection,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "uid", Value: 1}}, Options: options.Index().SetUnique(true)},
+				// reverse lookups: who has user as friend, user removal
+				{Keys: bson.D{{Key: "friend_ids", Value: 1}}},
+			},
+		},
+		{
+			collection: d.friendRequestsCollection,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "uid", Value: 1}}, Options: options.Index().SetUnique(true)},
+				// outgoing requests are found by sender in recipients documents
+				{Keys: bson.D{{Key: "friend_ids", Value: 1}}},
+			},
+		},
+		{
+			collection: d.abuseSignalsCollection,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "uid", Value: 1}}, Options: options.Index().SetUnique(true)},
+			},
+		},
+		{
+			collection: d.auditCollection,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "at", Value: -1}}},
+				{Keys: bson.D{{Key: "target", Value: 1}, {Key: "at", Value: -1}}},
+			},
+		},
+		{
+			collection: d.limitsCollection,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "uid", Value: 1}}, Options: options.Index().SetUnique(true)},
+			},
+		},
+		{
+			collection: d.countsCollection,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "uid", Value: 1}}, Options: options.Index().SetUnique(true)},
+			},
+		},
+		{
+			collection: d.followsCollection,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "follower", Value: 1}, {Key: "followee", Value: 1}}, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "follower", Value: 1}, {Key: "created_at", Value: -1}}},
+				{Keys: bson.D{{Key: "followee", Value: 1}, {Key: "created_at", Value: -1}}},
+			},
+		},
+		{
+			collection: d.blocksCollection,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "blocker", Value: 1}, {Key: "blocked", Value: 1}}, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "blocker", Value: 1}, {Key: "created_at", Value: -1}}},
+				{Keys: bson.D{{Key: "blocked", Value: 1}}},
+			},
+		},
+		{
+			collection: d.settingsCollection,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "uid", Value: 1}}, Options: options.Index().SetUnique(true)},
+			},
+		},
+		{
+			collection: d.invitesCollection,
+			models: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "inviter", Value: 1}, {Key: "created_at", Value: -1}}},
+				// expired invites are removed by mongo
+				{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+			},
+		},
+	}
+
+	for _, index := range indexes {
+		_, err := index.collection.Indexes().CreateMany(ctx, index.models)
+		if err != nil {
+			return fmt.Errorf("create %s indexes: %w", index.collection.Name(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/audit"
+	"github.com/daniilty/sharenote-friends/internal/slice"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Invite modes.
+const (
+	// InviteModeFriend - redeeming makes users friends.
+	InviteModeFriend = "friend"
+	// InviteModeRequest - redeeming sends friend request to inviter.
+	InviteModeRequest = "request"
+)
+
+// Invite - invite link issued by user, documents are dropped by TTL index after expiry.
+type Invite struct {
+	ID        string    `bson:"_id"`
+	Inviter   string    `bson:"inviter"`
+	Mode      string    `bson:"mode"`
+	SingleUse bool      `bson:"single_use"`
+	Redeemed  int       `bson:"redeemed"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// InsertInvite - store new invite.
+func (d *DBImpl) InsertInvite(ctx context.Context, invite *Invite) error {
+	ctx, end := startOperation(ctx, "insert_invite")
+	defer end()
+
+	_, err := d.invitesCollection.InsertOne(ctx, invite)
+
+	return err
+}
+
+// GetInvites - get newest first invites of user that have not expired yet.
+func (d *DBImpl) GetInvites(ctx context.Context, uid string) ([]*Invite, error) {
+	ctx, end := startOperation(ctx, "get_invites")
+	defer end()
+
+	// TTL monitor runs once a minute, so expired documents can still be present
+	filter := bson.D{
+		{Key: "inviter", Value: uid},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now()}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := d.invitesCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invites := []*Invite{}
+
+	err = cursor.All(ctx, &invites)
+	if err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+// RevokeInvite - delete invite issued by user.
+func (d *DBImpl) RevokeInvite(ctx context.Context, uid string, id string) (bool, error) {
+	ctx, end := startOperation(ctx, "revoke_invite")
+	defer end()
+
+	filter := bson.D{{Key: "_id", Value: id}, {Key: "inviter", Value: uid}}
+
+	res, err := d.invitesCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	if res.DeletedCount == 0 {
+		return true, errInviteNotFound
+	}
+
+	return true, nil
+}
+
+// RedeemInvite - make transaction applying invite for uid, returns redeemed invite.
+func (d *DBImpl) RedeemInvite(ctx context.Context, id string, uid string) (*Invite, bool, error) {
+	invite := &Invite{}
+
+	err := d.withTransaction(ctx, "redeem_invite", d.getRedeemInviteTransaction(id, uid, invite))
+	if err != nil {
+		if errors.Is(err, errInviteNotFound) || errors.Is(err, errSelfInvite) ||
+			errors.Is(err, errAlreadyFriends) || errors.Is(err, errAlreadyRequested) || errors.Is(err, errBlocked) ||
+			isLimitError(err) {
+			return nil, true, err
+		}
+
+		return nil, false, err
+	}
+
+	return invite, true, nil
+}
+
+// DeleteUsersInvites - delete invites issued by user.
+func (d *DBImpl) DeleteUsersInvites(ctx context.Context, uid string) error {
+	ctx, end := startOperation(ctx, "delete_users_invites")
+	defer end()
+
+	_, err := d.invitesCollection.DeleteMany(ctx, bson.D{{Key: "inviter", Value: uid}})
+
+	return err
+}
+
+func (d *DBImpl) getRedeemInviteTransaction(id string, uid string, invite *Invite) transactionFunc {
+	return func(sessCtx mongo.SessionContext) (interface{}, error) {
+		filter := bson.D{
+			{Key: "_id", Value: id},
+			{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now()}}},
+		}
+
+		err := d.invitesCollection.FindOne(sessCtx, filter).Decode(invite)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, errInviteNotFound
+			}
+
+			return nil, fmt.Errorf("get invite: %w", err)
+		}
+
+		if invite.Inviter == uid {
+			return nil, errSelfInvite
+		}
+
+		if invite.SingleUse {
+			_, err = d.invitesCollection.DeleteOne(sessCtx, bson.D{{Key: "_id", Value: id}})
+		} else {
+			_, err = d.invitesCollection.UpdateOne(sessCtx, bson.D{{Key: "_id", Value: id}},
+				bson.D{{Key: "$inc", Value: bson.D{{Key: "redeemed", Value: 1}}}})
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("use invite: %w", err)
+		}
+
+		invite.Redeemed++
+
+		friends, err := d.GetFriends(sessCtx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("get friends: %w", err)
+		}
+
+		if slice.ContainsString(friends.FriendIDs, invite.Inviter) {
+			return nil, errAlreadyFriends
+		}
+
+		err = d.checkNotBlocked(sessCtx, uid, invite.Inviter)
+		if err != nil {
+			return nil, err
+		}
+
+		if invite.Mode == InviteModeRequest {
+			return d.getRequestFriendTransaction(uid, invite.Inviter)(sessCtx)
+		}
+
+		err = d.checkFriendsLimit(sessCtx, uid, len(friends.FriendIDs))
+		if err != nil {
+			return nil, err
+		}
+
+		inviterFriends, err := d.GetFriends(sessCtx, invite.Inviter)
+		if err != nil {
+			return nil, fmt.Errorf("get inviter friends: %w", err)
+		}
+
+		err = d.checkFriendsLimit(sessCtx, invite.Inviter, len(inviterFriends.FriendIDs))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.getMakeFriendsTransaction(uid, invite.Inviter, audit.ActionFriendInviteRedeemed)(sessCtx)
+	}
+}
@@ -0,0 +1,185 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Limit names.
+const (
+	LimitFriends         = "friends"
+	LimitIncomingPending = "incoming_pending"
+	LimitOutgoingPending = "outgoing_pending"
+)
+
+// Limits - max friends and pending requests per user, zero disables limit.
+type Limits struct {
+	MaxFriends         int
+	MaxIncomingPending int
+	MaxOutgoingPending int
+}
+
+// LimitsOverride - per user limits, nil field keeps default one.
+type LimitsOverride struct {
+	UID                string    `bson:"uid"`
+	MaxFriends         *int      `bson:"max_friends,omitempty"`
+	MaxIncomingPending *int      `bson:"max_incoming_pending,omitempty"`
+	MaxOutgoingPending *int      `bson:"max_outgoing_pending,omitempty"`
+	Reason             string    `bson:"reason,omitempty"`
+	UpdatedAt          time.Time `bson:"updated_at"`
+}
+
+// IsEmpty - override changes no limit.
+func (o *LimitsOverride) IsEmpty() bool {
+	return o.MaxFriends == nil && o.MaxIncomingPending == nil && o.MaxOutgoingPending == nil
+}
+
+// apply - defaults with overridden values replaced.
+func (o *LimitsOverride) apply(l Limits) Limits {
+	if o.MaxFriends != nil {
+		l.MaxFriends = *o.MaxFriends
+	}
+
+	if o.MaxIncomingPending != nil {
+		l.MaxIncomingPending = *o.MaxIncomingPending
+	}
+
+	if o.MaxOutgoingPending != nil {
+		l.MaxOutgoingPending = *o.MaxOutgoingPending
+	}
+
+	return l
+}
+
+// LimitError - change would take user over friends or pending requests limit.
+type LimitError struct {
+	Limit string
+	UID   string
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("user %s reached %s limit of %d", e.UID, e.Limit, e.Max)
+}
+
+// GetLimits - get user limits, defaults with user override applied.
+func (d *DBImpl) GetLimits(ctx context.Context, uid string) (Limits, error) {
+	override, err := d.GetLimitsOverride(ctx, uid)
+	if err != nil {
+		return Limits{}, err
+	}
+
+	if override == nil {
+		return d.limits, nil
+	}
+
+	return override.apply(d.limits), nil
+}
+
+// GetLimitsOverride - get user limits override, nil when there is none.
+func (d *DBImpl) GetLimitsOverride(ctx context.Context, uid string) (*LimitsOverride, error) {
+	ctx, end := startOperation(ctx, "get_limits_override")
+	defer end()
+
+	override := &LimitsOverride{}
+
+	err := d.limitsCollection.FindOne(ctx, bson.D{{Key: "uid", Value: uid}}).Decode(override)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return override, nil
+}
+
+// SetLimitsOverride - replace user limits override, empty override removes it.
+func (d *DBImpl) SetLimitsOverride(ctx context.Context, override *LimitsOverride) error {
+	ctx, end := startOperation(ctx, "set_limits_override")
+	defer end()
+
+	filter := bson.D{{Key: "uid", Value: override.UID}}
+
+	if override.IsEmpty() {
+		_, err := d.limitsCollection.DeleteOne(ctx, filter)
+
+		return err
+	}
+
+	override.UpdatedAt = now()
+
+	_, err := d.limitsCollection.ReplaceOne(ctx, filter, override, options.Replace().SetUpsert(true))
+
+	return err
+}
+
+// DeleteLimitsOverride - delete user limits override.
+func (d *DBImpl) DeleteLimitsOverride(ctx context.Context, uid string) error {
+	ctx, end := startOperation(ctx, "delete_limits_override")
+	defer end()
+
+	_, err := d.limitsCollection.DeleteOne(ctx, bson.D{{Key: "uid", Value: uid}})
+
+	return err
+}
+
+// checkFriendsLimit - fail when user having count friends cannot get one more.
+func (d *DBImpl) checkFriendsLimit(sessCtx mongo.SessionContext, uid string, count int) error {
+	limits, err := d.GetLimits(sessCtx, uid)
+	if err != nil {
+		return fmt.Errorf("get %s limits: %w", uid, err)
+	}
+
+	if limits.MaxFriends > 0 && count >= limits.MaxFriends {
+		return &LimitError{Limit: LimitFriends, UID: uid, Max: limits.MaxFriends}
+	}
+
+	return nil
+}
+
+// checkRequestLimits - fail when sender has too many unanswered requests
+// or recipient having incoming pending requests cannot get one more.
+func (d *DBImpl) checkRequestLimits(sessCtx mongo.SessionContext, from string, to string, incoming int) error {
+	toLimits, err := d.GetLimits(sessCtx, to)
+	if err != nil {
+		return fmt.Errorf("get %s limits: %w", to, err)
+	}
+
+	if toLimits.MaxIncomingPending > 0 && incoming >= toLimits.MaxIncomingPending {
+		return &LimitError{Limit: LimitIncomingPending, UID: to, Max: toLimits.MaxIncomingPending}
+	}
+
+	fromLimits, err := d.GetLimits(sessCtx, from)
+	if err != nil {
+		return fmt.Errorf("get %s limits: %w", from, err)
+	}
+
+	if fromLimits.MaxOutgoingPending <= 0 {
+		return nil
+	}
+
+	counts, err := d.GetCounts(sessCtx, []string{from})
+	if err != nil {
+		return fmt.Errorf("get %s counts: %w", from, err)
+	}
+
+	if counts[0].OutgoingPending >= int64(fromLimits.MaxOutgoingPending) {
+		return &LimitError{Limit: LimitOutgoingPending, UID: from, Max: fromLimits.MaxOutgoingPending}
+	}
+
+	return nil
+}
+
+func isLimitError(err error) bool {
+	var limitErr *LimitError
+
+	return errors.As(err, &limitErr)
+}
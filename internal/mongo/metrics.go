@@ -0,0 +1,44 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName - instrumentation name of mongo spans.
+const tracerName = "github.com/daniilty/sharenote-friends/internal/mongo"
+
+var (
+	mongoOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "friends_mongo_operation_duration_seconds",
+		Help: "Mongo operation latency by DB method.",
+	}, []string{"operation"})
+	mongoTransactionRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "friends_mongo_transaction_retries_total",
+		Help: "Mongo transaction callback retries by transaction.",
+	}, []string{"transaction"})
+)
+
+// startOperation - start operation span, returned func records latency and ends span.
+func startOperation(ctx context.Context, operation string) (context.Context, func()) {
+	start := time.Now()
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "mongo."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMongoDB,
+			semconv.DBOperationKey.String(operation),
+		),
+	)
+
+	return ctx, func() {
+		span.End()
+		mongoOperationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,84 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// UserReferences - documents RemoveUser would change, both user own ones
+// and other users documents referencing user.
+type UserReferences struct {
+	// Friends - ids in user friends document.
+	Friends int
+	// IncomingRequests - ids in user requests document.
+	IncomingRequests int
+	// FriendOf - friends documents of other users having user.
+	FriendOf int64
+	// OutgoingRequests - requests documents of other users having user.
+	OutgoingRequests int64
+	Following        int64
+	Followers        int64
+	// Blocks - blocks made by or of user.
+	Blocks         int64
+	Invites        int64
+	LimitsOverride int64
+	Counts         int64
+	Settings       int64
+}
+
+// IsEmpty - user is not referenced by any document.
+func (r *UserReferences) IsEmpty() bool {
+	return *r == UserReferences{}
+}
+
+// GetUserReferences - count documents referencing user.
+func (d *DBImpl) GetUserReferences(ctx context.Context, uid string) (*UserReferences, error) {
+	ctx, end := startOperation(ctx, "get_user_references")
+	defer end()
+
+	friends, err := d.GetFriends(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get friends: %w", err)
+	}
+
+	requests, err := d.GetFriendRequests(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get friend requests: %w", err)
+	}
+
+	refs := &UserReferences{
+		Friends:          len(friends.FriendIDs),
+		IncomingRequests: len(requests.FriendIDs),
+	}
+
+	counts := []struct {
+		collection *mongo.Collection
+		filter     bson.D
+		count      *int64
+	}{
+		{d.friendsCollection, bson.D{{Key: "friend_ids", Value: uid}}, &refs.FriendOf},
+		{d.friendRequestsCollection, bson.D{{Key: "friend_ids", Value: uid}}, &refs.OutgoingRequests},
+		{d.followsCollection, bson.D{{Key: "follower", Value: uid}}, &refs.Following},
+		{d.followsCollection, bson.D{{Key: "followee", Value: uid}}, &refs.Followers},
+		{d.blocksCollection, bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "blocker", Value: uid}},
+			bson.D{{Key: "blocked", Value: uid}},
+		}}}, &refs.Blocks},
+		{d.invitesCollection, bson.D{{Key: "inviter", Value: uid}}, &refs.Invites},
+		{d.limitsCollection, bson.D{{Key: "uid", Value: uid}}, &refs.LimitsOverride},
+		{d.countsCollection, bson.D{{Key: "uid", Value: uid}}, &refs.Counts},
+		{d.settingsCollection, bson.D{{Key: "uid", Value: uid}}, &refs.Settings},
+	}
+
+	for _, c := range counts {
+		*c.count, err = c.collection.CountDocuments(ctx, c.filter)
+		if err != nil {
+			return nil, fmt.Errorf("count %s documents: %w", c.collection.Name(), err)
+		}
+	}
+
+	return refs, nil
+}
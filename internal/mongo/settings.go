@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Settings - user privacy settings.
+type Settings struct {
+	UID string `bson:"uid"`
+	// HideConnections - friends of user are not used to connect other users.
+	HideConnections bool      `bson:"hide_connections"`
+	UpdatedAt       time.Time `bson:"updated_at"`
+}
+
+// GetSettings - get user settings, defaults when user has not changed them.
+func (d *DBImpl) GetSettings(ctx context.Context, uid string) (*Settings, error) {
+	ctx, end := startOperation(ctx, "get_settings")
+	defer end()
+
+	settings := &Settings{}
+
+	err := d.settingsCollection.FindOne(ctx, bson.D{{Key: "uid", Value: uid}}).Decode(settings)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return &Settings{UID: uid}, nil
+		}
+
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// SetSettings - replace user settings.
+func (d *DBImpl) SetSettings(ctx context.Context, settings *Settings) error {
+	ctx, end := startOperation(ctx, "set_settings")
+	defer end()
+
+	settings.UpdatedAt = now()
+
+	filter := bson.D{{Key: "uid", Value: settings.UID}}
+
+	_, err := d.settingsCollection.ReplaceOne(ctx, filter, settings, options.Replace().SetUpsert(true))
+
+	return err
+}
+
+// GetHiddenConnectionsIDs - ids of uids that hide their connections.
+func (d *DBImpl) GetHiddenConnectionsIDs(ctx context.Context, uids []string) ([]string, error) {
+	ctx, end := startOperation(ctx, "get_hidden_connections_ids")
+	defer end()
+
+	filter := bson.D{
+		{Key: "uid", Value: bson.D{{Key: "$in", Value: uids}}},
+		{Key: "hide_connections", Value: true},
+	}
+
+	values, err := d.settingsCollection.Distinct(ctx, "uid", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(values))
+
+	for _, v := range values {
+		id, ok := v.(string)
+		if !ok {
+			return nil, errors.New("uid is not a string")
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// DeleteSettings - delete user settings.
+func (d *DBImpl) DeleteSettings(ctx context.Context, uid string) error {
+	ctx, end := startOperation(ctx, "delete_settings")
+	defer end()
+
+	_, err := d.settingsCollection.DeleteOne(ctx, bson.D{{Key: "uid", Value: uid}})
+
+	return err
+}
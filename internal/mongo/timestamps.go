@@ -0,0 +1,153 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UnknownTime - sentinel timestamp of friendships and requests created before timestamps were recorded.
+var UnknownTime = time.Unix(0, 0).UTC()
+
+// TimestampsBackfill - ids without timestamp found in collection.
+type TimestampsBackfill struct {
+	Collection string
+	Documents  int
+	IDs        int
+}
+
+type timesDocument struct {
+	UID         string               `bson:"uid"`
+	FriendIDs   []string             `bson:"friend_ids"`
+	Since       map[string]time.Time `bson:"since"`
+	RequestedAt map[string]time.Time `bson:"requested_at"`
+}
+
+// now - current time with precision kept by mongo.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// timesField - field keeping per id timestamps in collection documents.
+func (d *DBImpl) timesField(collection *mongo.Collection) string {
+	if collection == d.friendRequestsCollection {
+		return "requested_at"
+	}
+
+	return "since"
+}
+
+// GetOutgoingRequestedAt - get creation time of pending requests sent by user by recipient id.
+func (d *DBImpl) GetOutgoingRequestedAt(ctx context.Context, uid string) (map[string]time.Time, error) {
+	ctx, end := startOperation(ctx, "get_outgoing_requested_at")
+	defer end()
+
+	filter := bson.M{"friend_ids": uid}
+	opts := options.Find().SetProjection(bson.M{"_id": 0, "uid": 1, "requested_at." + uid: 1})
+
+	cursor, err := d.friendRequestsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	times := map[string]time.Time{}
+
+	for cursor.Next(ctx) {
+		doc := &timesDocument{}
+
+		err = cursor.Decode(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		at, ok := doc.RequestedAt[uid]
+		if ok {
+			times[doc.UID] = at
+		}
+	}
+
+	return times, cursor.Err()
+}
+
+// BackfillTimestamps - set UnknownTime for friends and requests stored without timestamp,
+// only counts them unless apply is set.
+func (d *DBImpl) BackfillTimestamps(ctx context.Context, apply bool) ([]*TimestampsBackfill, error) {
+	ctx, end := startOperation(ctx, "backfill_timestamps")
+	defer end()
+
+	res := []*TimestampsBackfill{}
+
+	for _, collection := range []*mongo.Collection{d.friendsCollection, d.friendRequestsCollection} {
+		backfill, err := d.backfillCollectionTimestamps(ctx, collection, apply)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, backfill)
+	}
+
+	return res, nil
+}
+
+func (d *DBImpl) backfillCollectionTimestamps(ctx context.Context, collection *mongo.Collection, apply bool) (*TimestampsBackfill, error) {
+	field := d.timesField(collection)
+	backfill := &TimestampsBackfill{
+		Collection: collection.Name(),
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 0})
+
+	cursor, err := collection.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		doc := &timesDocument{}
+
+		err = cursor.Decode(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		times := doc.Since
+		if field == "requested_at" {
+			times = doc.RequestedAt
+		}
+
+		missing := bson.D{}
+
+		for _, id := range doc.FriendIDs {
+			_, ok := times[id]
+			if !ok {
+				missing = append(missing, bson.E{Key: field + "." + id, Value: UnknownTime})
+			}
+		}
+
+		if len(missing) == 0 {
+			continue
+		}
+
+		backfill.Documents++
+		backfill.IDs += len(missing)
+
+		if !apply {
+			continue
+		}
+
+		filter := bson.D{{Key: "uid", Value: doc.UID}}
+		update := bson.D{{Key: "$set", Value: missing}}
+
+		_, err = collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return backfill, cursor.Err()
+}
@@ -1,5 +1,35 @@
 package mongo
 
-import "go.mongodb.org/mongo-driver/mongo"
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
 
 type transactionFunc func(mongo.SessionContext) (interface{}, error)
+
+// withTransaction - run transaction in new session, counting driver retries.
+func (d *DBImpl) withTransaction(ctx context.Context, name string, transaction transactionFunc) error {
+	ctx, end := startOperation(ctx, "transaction."+name)
+	defer end()
+
+	session, err := d.mongoDB.Client().StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	attempts := 0
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		attempts++
+		if attempts > 1 {
+			mongoTransactionRetries.WithLabelValues(name).Inc()
+		}
+
+		return transaction(sessCtx)
+	})
+
+	return err
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// BackendMemory - per replica in-process counters.
+	BackendMemory = "memory"
+	// BackendRedis - counters shared by every replica.
+	BackendRedis = "redis"
+)
+
+// Config - per user actions rate limit config, zero limit disables window.
+type Config struct {
+	Backend   string
+	PerMinute int64
+	PerDay    int64
+}
+
+// Validate - check config consistency.
+func (c *Config) Validate() error {
+	switch c.Backend {
+	case BackendMemory, BackendRedis:
+	default:
+		return fmt.Errorf("rate limit: unsupported backend %q", c.Backend)
+	}
+
+	if c.PerMinute < 0 || c.PerDay < 0 {
+		return errors.New("rate limit: limits cannot be negative")
+	}
+
+	return nil
+}
+
+// Windows - minute and day windows built from config.
+func (c *Config) Windows() []Window {
+	return []Window{
+		{
+			Name:   "minute",
+			Period: time.Minute,
+			Limit:  c.PerMinute,
+		},
+		{
+			Name:   "day",
+			Period: 24 * time.Hour,
+			Limit:  c.PerDay,
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	_ Limiter = (*WindowLimiter)(nil)
+	_ Limiter = Unlimited{}
+)
+
+// Limiter - per key actions limiter.
+type Limiter interface {
+	// Allow - count action for key, returns time to wait when limit is exceeded.
+	Allow(ctx context.Context, key string) (bool, time.Duration, error)
+}
+
+// Window - max actions per window.
+type Window struct {
+	Name   string
+	Period time.Duration
+	Limit  int64
+}
+
+// WindowLimiter - fixed windows limiter, every window must allow action.
+type WindowLimiter struct {
+	store   Store
+	prefix  string
+	windows []Window
+}
+
+// NewWindowLimiter - WindowLimiter constructor, windows with zero limit are skipped.
+func NewWindowLimiter(store Store, prefix string, windows ...Window) *WindowLimiter {
+	enabled := make([]Window, 0, len(windows))
+
+	for i := range windows {
+		if windows[i].Limit > 0 {
+			enabled = append(enabled, windows[i])
+		}
+	}
+
+	return &WindowLimiter{
+		store:   store,
+		prefix:  prefix,
+		windows: enabled,
+	}
+}
+
+func (w *WindowLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	var retryAfter time.Duration
+
+	for _, window := range w.windows {
+		count, ttl, err := w.store.Incr(ctx, w.prefix+":"+window.Name+":"+key, window.Period)
+		if err != nil {
+			return false, 0, err
+		}
+
+		if count > window.Limit && ttl > retryAfter {
+			retryAfter = ttl
+		}
+	}
+
+	return retryAfter == 0, retryAfter, nil
+}
+
+// Unlimited - limiter allowing everything.
+type Unlimited struct{}
+
+func (Unlimited) Allow(context.Context, string) (bool, time.Duration, error) {
+	return true, 0, nil
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*RedisStore)(nil)
+)
+
+// Store - fixed window counters storage.
+type Store interface {
+	// Incr - increment counter for key, window starts on first increment.
+	// Returns counter value and time left until window resets.
+	Incr(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error)
+}
+
+type memoryCounter struct {
+	count   int64
+	resetAt time.Time
+}
+
+// MemoryStore - in-process store, limits are per replica.
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*memoryCounter
+	lastGC   time.Time
+}
+
+// NewMemoryStore - MemoryStore constructor.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters: map[string]*memoryCounter{},
+		lastGC:   time.Now(),
+	}
+}
+
+func (m *MemoryStore) Incr(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.gc(now)
+
+	c, ok := m.counters[key]
+	if !ok || !now.Before(c.resetAt) {
+		c = &memoryCounter{
+			resetAt: now.Add(window),
+		}
+		m.counters[key] = c
+	}
+
+	c.count++
+
+	return c.count, c.resetAt.Sub(now), nil
+}
+
+// gc - drop expired counters at most once a minute.
+func (m *MemoryStore) gc(now time.Time) {
+	if now.Sub(m.lastGC) < time.Minute {
+		return
+	}
+
+	for key, c := range m.counters {
+		if !now.Before(c.resetAt) {
+			delete(m.counters, key)
+		}
+	}
+
+	m.lastGC = now
+}
+
+const redisKeyPrefix = "friends:ratelimit:"
+
+// RedisStore - store shared by every replica.
+type RedisStore struct {
+	redis redis.Cmdable
+}
+
+// NewRedisStore - RedisStore constructor.
+func NewRedisStore(r redis.Cmdable) *RedisStore {
+	return &RedisStore{
+		redis: r,
+	}
+}
+
+func (r *RedisStore) Incr(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	key = redisKeyPrefix + key
+
+	count, err := r.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if count == 1 {
+		err = r.redis.PExpire(ctx, key, window).Err()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return count, window, nil
+	}
+
+	ttl, err := r.redis.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if ttl < 0 {
+		// expire was lost, e.g. crash between INCR and PEXPIRE
+		err = r.redis.PExpire(ctx, key, window).Err()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		ttl = window
+	}
+
+	return count, ttl, nil
+}
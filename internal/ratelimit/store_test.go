@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisStore(client), srv
+}
+
+func TestRedisStoreIncr(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newTestRedisStore(t)
+
+	for want := int64(1); want <= 3; want++ {
+		count, ttl, err := store.Incr(ctx, "user", time.Minute)
+		if err != nil {
+			t.Fatalf("incr: %v", err)
+		}
+
+		if count != want {
+			t.Fatalf("got count %d, want %d", count, want)
+		}
+
+		if ttl <= 0 || ttl > time.Minute {
+			t.Fatalf("got ttl %s, want (0, 1m]", ttl)
+		}
+	}
+
+	// window resets once key expires
+	srv.FastForward(time.Minute)
+
+	count, _, err := store.Incr(ctx, "user", time.Minute)
+	if err != nil {
+		t.Fatalf("incr: %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("got count %d after window reset, want 1", count)
+	}
+}
+
+func TestRedisStoreRestoresLostExpire(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newTestRedisStore(t)
+
+	// counter left without expire, e.g. crash between INCR and PEXPIRE
+	err := srv.Set(redisKeyPrefix+"user", "5")
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	count, ttl, err := store.Incr(ctx, "user", time.Minute)
+	if err != nil {
+		t.Fatalf("incr: %v", err)
+	}
+
+	if count != 6 {
+		t.Fatalf("got count %d, want 6", count)
+	}
+
+	if ttl != time.Minute {
+		t.Fatalf("got ttl %s, want 1m", ttl)
+	}
+
+	if got := srv.TTL(redisKeyPrefix + "user"); got != time.Minute {
+		t.Fatalf("got key ttl %s, want 1m", got)
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// AdminConfig - admin http server config.
+type AdminConfig struct {
+	Addr string
+	// AuditToken - bearer token required by audit route, empty disables route.
+	AuditToken string
+}
+
+// Admin - operational http server, not exposed to users.
+type Admin struct {
+	innerServer *http.Server
+
+	logger     *zap.SugaredLogger
+	service    core.Service
+	auditToken []byte
+}
+
+func (a *Admin) Run(ctx context.Context) {
+	a.logger.Infow("Admin HTTP server starting.", "addr", a.innerServer.Addr)
+
+	go func() {
+		err := a.innerServer.ListenAndServe()
+		if err != nil {
+			if errors.Is(err, http.ErrServerClosed) {
+				return
+			}
+
+			a.logger.Errorw("Listen and serve admin HTTP", "addr", a.innerServer.Addr, "err", err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	a.logger.Info("Graceful admin server shutdown.")
+	a.innerServer.Shutdown(context.Background())
+}
+
+// NewAdmin - constructor.
+func NewAdmin(cfg AdminConfig, logger *zap.SugaredLogger, service core.Service) *Admin {
+	a := &Admin{
+		logger:     logger,
+		service:    service,
+		auditToken: []byte(cfg.AuditToken),
+	}
+
+	r := mux.NewRouter()
+	r.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+
+	// audit exposes users friendship history, metrics scrapers must not read it
+	if cfg.AuditToken != "" {
+		r.Handle("/audit", a.requireAuditToken(http.HandlerFunc(a.auditHandler))).Methods(http.MethodGet)
+	}
+
+	a.innerServer = &http.Server{
+		Addr:    cfg.Addr,
+		Handler: r,
+	}
+
+	return a
+}
+
+// requireAuditToken - reject requests without audit bearer token.
+func (a *Admin) requireAuditToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		if subtle.ConstantTimeCompare([]byte(token), a.auditToken) != 1 {
+			getUnauthorizedErrorResponse().writeJSON(w)
+
+			return
+		}
+
+		a.logger.Infow("Audit read.", "uid", r.URL.Query().Get("uid"), "remote_addr", r.RemoteAddr)
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/gorilla/mux"
+)
+
+type annotation struct {
+	Nickname  string    `json:"nickname,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// setAnnotationRequest - empty nickname and note remove annotation.
+type setAnnotationRequest struct {
+	Nickname string `json:"nickname"`
+	Note     string `json:"note"`
+}
+
+func (h *HTTP) setAnnotationHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getSetAnnotationResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getSetAnnotationResponse(r *http.Request) response {
+	if r.Body == http.NoBody {
+		return getBadRequestWithMsgResponse("no body")
+	}
+
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	req := &setAnnotationRequest{}
+
+	err = unmarshalReader(r.Body, req)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	ok, err := h.service.SetAnnotation(r.Context(), c.UID, mux.Vars(r)["uid"], core.Annotation{
+		Nickname: req.Nickname,
+		Note:     req.Note,
+	})
+	if err != nil {
+		if ok {
+			return getBadRequestWithMsgResponse(err.Error())
+		}
+
+		h.requestLogger(r).Errorw("Set annotation.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return getEmptyOKResponse()
+}
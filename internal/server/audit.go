@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/core"
+)
+
+type auditEntry struct {
+	Actor  string    `json:"actor"`
+	Source string    `json:"source"`
+	Action string    `json:"action"`
+	UID    string    `json:"uid"`
+	Target string    `json:"target,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+type auditResponse struct {
+	Status string        `json:"status"`
+	Data   []*auditEntry `json:"data"`
+}
+
+func (a *auditResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, http.StatusOK, a)
+}
+
+func (a *Admin) auditHandler(w http.ResponseWriter, r *http.Request) {
+	resp := a.getAuditResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (a *Admin) getAuditResponse(r *http.Request) response {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	entries, err := a.service.GetAuditEntries(r.Context(), filter)
+	if err != nil {
+		a.logger.Errorw("Get audit entries.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	data := make([]*auditEntry, 0, len(entries))
+
+	for _, e := range entries {
+		data = append(data, &auditEntry{
+			Actor:  e.Actor,
+			Source: e.Source,
+			Action: e.Action,
+			UID:    e.UID,
+			Target: e.Target,
+			At:     e.At,
+		})
+	}
+
+	return &auditResponse{
+		Status: http.StatusText(http.StatusOK),
+		Data:   data,
+	}
+}
+
+func parseAuditFilter(r *http.Request) (core.AuditFilter, error) {
+	var err error
+
+	q := r.URL.Query()
+
+	filter := core.AuditFilter{
+		UID: q.Get("uid"),
+	}
+
+	if filter.UID == "" {
+		return filter, fmt.Errorf(`"uid": cannot be empty`)
+	}
+
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if q.Get(name) == "" {
+			continue
+		}
+
+		*dst, err = time.Parse(time.RFC3339, q.Get(name))
+		if err != nil {
+			return filter, fmt.Errorf(`"%s": must be RFC3339 time`, name)
+		}
+	}
+
+	if q.Get("limit") != "" {
+		filter.Limit, err = strconv.Atoi(q.Get("limit"))
+		if err != nil || filter.Limit <= 0 {
+			return filter, fmt.Errorf(`"limit": must be positive integer`)
+		}
+	}
+
+	return filter, nil
+}
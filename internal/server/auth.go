@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/daniilty/sharenote-auth/claims"
+	"github.com/daniilty/sharenote-friends/internal/audit"
+	"github.com/daniilty/sharenote-friends/internal/auth"
+)
+
+// authenticate - get request subject, failures to check credentials are logged.
+func (h *HTTP) authenticate(r *http.Request) (*claims.Subject, error) {
+	sub, err := h.authenticator.Authenticate(r)
+	if err != nil && !errors.Is(err, auth.ErrUnauthenticated) {
+		h.requestLogger(r).Errorw("Authenticate.", "err", err)
+	}
+
+	return sub, err
+}
+
+// actorContext - request context with authenticated user as audit actor.
+func actorContext(r *http.Request, uid string) context.Context {
+	return audit.WithActor(r.Context(), audit.Actor{
+		ID:     uid,
+		Source: audit.SourceHTTP,
+	})
+}
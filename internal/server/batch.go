@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/daniilty/sharenote-friends/internal/slice"
+)
+
+type batchRequest struct {
+	FriendIDs []string `json:"friend_ids"`
+}
+
+func (r *batchRequest) validate(uid string) error {
+	if len(r.FriendIDs) == 0 {
+		return fmt.Errorf(`"friend_ids": cannot be empty`)
+	}
+
+	if len(r.FriendIDs) > core.MaxRequestsBatch {
+		return fmt.Errorf(`"friend_ids": at most %d ids are allowed`, core.MaxRequestsBatch)
+	}
+
+	for i, id := range r.FriendIDs {
+		if id == "" {
+			return fmt.Errorf(`"friend_ids[%d]": cannot be empty`, i)
+		}
+
+		if id == uid {
+			return fmt.Errorf(`"friend_ids[%d]": you cannot be friend with yourself`, i)
+		}
+
+		if slice.ContainsString(r.FriendIDs[:i], id) {
+			return fmt.Errorf(`"friend_ids[%d]": duplicate id %q`, i, id)
+		}
+	}
+
+	return nil
+}
+
+type batchResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type batchResponse struct {
+	Status string         `json:"status"`
+	Data   []*batchResult `json:"data"`
+}
+
+func (b *batchResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, http.StatusOK, b)
+}
+
+func (h *HTTP) acceptFriendsBatchHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getBatchResponse(r, "Accept friend requests batch.", h.service.AcceptFriendRequests)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) declineFriendsBatchHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getBatchResponse(r, "Decline friend requests batch.", h.service.DeclineFriendRequests)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getBatchResponse(r *http.Request, logMsg string, handle func(ctx context.Context, uid string, ids []string) ([]*core.BatchResult, error)) response {
+	if r.Body == http.NoBody {
+		return getBadRequestWithMsgResponse("no body")
+	}
+
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	req := &batchRequest{}
+
+	err = unmarshalReader(r.Body, req)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	err = req.validate(c.UID)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	results, err := handle(actorContext(r, c.UID), c.UID, req.FriendIDs)
+	if err != nil {
+		h.requestLogger(r).Errorw(logMsg, "err", err, "handled", len(results))
+
+		return getInternalServerErrorResponse()
+	}
+
+	data := make([]*batchResult, 0, len(results))
+
+	for _, res := range results {
+		data = append(data, &batchResult{
+			ID:     res.ID,
+			Status: res.Status,
+		})
+	}
+
+	return &batchResponse{
+		Status: http.StatusText(http.StatusOK),
+		Data:   data,
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *HTTP) blockHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getBlockResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) unblockHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getUnblockResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getBlockedHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getBlockedResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getBlockResponse(r *http.Request) response {
+	if r.Body == http.NoBody {
+		return getBadRequestWithMsgResponse("no body")
+	}
+
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	// same body as follow request
+	req := &followRequest{}
+
+	err = unmarshalReader(r.Body, req)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	err = req.validate()
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	if req.UserID == c.UID {
+		return getBadRequestWithMsgResponse("you cannot block yourself")
+	}
+
+	ok, err := h.service.Block(actorContext(r, c.UID), c.UID, req.UserID)
+	if err != nil {
+		if ok {
+			return getBadRequestWithMsgResponse(err.Error())
+		}
+
+		h.requestLogger(r).Errorw("Block.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return getEmptyOKResponse()
+}
+
+func (h *HTTP) getUnblockResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	ok, err := h.service.Unblock(actorContext(r, c.UID), c.UID, mux.Vars(r)["uid"])
+	if err != nil {
+		if ok {
+			return getBadRequestWithMsgResponse(err.Error())
+		}
+
+		h.requestLogger(r).Errorw("Unblock.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return getEmptyOKResponse()
+}
+
+func (h *HTTP) getBlockedResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	users, err := h.service.GetBlocked(r.Context(), c.UID)
+	if err != nil {
+		h.requestLogger(r).Errorw("Get blocked.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return convertCoreUsersToResponse(users)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/daniilty/sharenote-friends/internal/core"
 )
@@ -19,9 +20,41 @@ func convertCoreUsersToResponse(uu []*core.User) *friendsResponse {
 	}
 }
 
+// convertCoreFriendsToResponse - convert friends list, unlike other lists it has favorite flag
+// and annotations.
+func convertCoreFriendsToResponse(uu []*core.User) *friendsResponse {
+	resp := convertCoreUsersToResponse(uu)
+
+	for i := range resp.Data {
+		isFavorite := uu[i].IsFavorite
+		resp.Data[i].IsFavorite = &isFavorite
+
+		if a := uu[i].Annotation; a != nil {
+			resp.Data[i].Annotation = &annotation{
+				Nickname:  a.Nickname,
+				Note:      a.Note,
+				UpdatedAt: a.UpdatedAt,
+			}
+		}
+	}
+
+	return resp
+}
+
 func convertCoreUserToResponse(u *core.User) *friend {
 	return &friend{
-		ID:   u.ID,
-		Name: u.Name,
+		ID:          u.ID,
+		Name:        u.Name,
+		Since:       timePtr(u.Since),
+		RequestedAt: timePtr(u.RequestedAt),
 	}
 }
+
+// timePtr - nil for zero time, unknown timestamps are omitted.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/daniilty/sharenote-friends/internal/slice"
+)
+
+type countsBatchRequest struct {
+	UIDs []string `json:"uids"`
+}
+
+func (r *countsBatchRequest) validate() error {
+	if len(r.UIDs) == 0 {
+		return fmt.Errorf(`"uids": cannot be empty`)
+	}
+
+	if len(r.UIDs) > core.MaxCountsBatch {
+		return fmt.Errorf(`"uids": at most %d ids are allowed`, core.MaxCountsBatch)
+	}
+
+	for i, id := range r.UIDs {
+		if id == "" {
+			return fmt.Errorf(`"uids[%d]": cannot be empty`, i)
+		}
+
+		if slice.ContainsString(r.UIDs[:i], id) {
+			return fmt.Errorf(`"uids[%d]": duplicate id %q`, i, id)
+		}
+	}
+
+	return nil
+}
+
+type counts struct {
+	UID             string `json:"uid"`
+	Friends         int64  `json:"friends"`
+	IncomingPending int64  `json:"incoming_pending"`
+	OutgoingPending int64  `json:"outgoing_pending"`
+}
+
+type countsResponse struct {
+	Status string  `json:"status"`
+	Data   *counts `json:"data"`
+}
+
+func (c *countsResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, http.StatusOK, c)
+}
+
+// friendsCount - public part of other user counts.
+type friendsCount struct {
+	UID     string `json:"uid"`
+	Friends int64  `json:"friends"`
+}
+
+type countsBatchResponse struct {
+	Status string          `json:"status"`
+	Data   []*friendsCount `json:"data"`
+}
+
+func (c *countsBatchResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, http.StatusOK, c)
+}
+
+func (h *HTTP) getCountsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getCountsResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getCountsBatchHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getCountsBatchResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getCountsResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	cc, err := h.service.GetCounts(r.Context(), c.UID)
+	if err != nil {
+		h.requestLogger(r).Errorw("Get counts.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return &countsResponse{
+		Status: http.StatusText(http.StatusOK),
+		Data: &counts{
+			UID:             cc.UID,
+			Friends:         cc.Friends,
+			IncomingPending: cc.IncomingPending,
+			OutgoingPending: cc.OutgoingPending,
+		},
+	}
+}
+
+func (h *HTTP) getCountsBatchResponse(r *http.Request) response {
+	if r.Body == http.NoBody {
+		return getBadRequestWithMsgResponse("no body")
+	}
+
+	_, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	req := &countsBatchRequest{}
+
+	err = unmarshalReader(r.Body, req)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	err = req.validate()
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	cc, err := h.service.GetFriendsCounts(r.Context(), req.UIDs)
+	if err != nil {
+		h.requestLogger(r).Errorw("Get counts batch.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	data := make([]*friendsCount, 0, len(cc))
+
+	for _, c := range cc {
+		data = append(data, &friendsCount{
+			UID:     c.UID,
+			Friends: c.Friends,
+		})
+	}
+
+	return &countsBatchResponse{
+		Status: http.StatusText(http.StatusOK),
+		Data:   data,
+	}
+}
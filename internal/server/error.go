@@ -1,7 +1,12 @@
 package server
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/core"
 )
 
 type errorResponse struct {
@@ -20,6 +25,13 @@ func getBadRequestWithMsgResponse(msg string) errorResponse {
 	}
 }
 
+func getNotFoundWithMsgResponse(msg string) errorResponse {
+	return errorResponse{
+		Status:    http.StatusNotFound,
+		ErrorInfo: msg,
+	}
+}
+
 func getInternalServerErrorResponse() errorResponse {
 	return getInternalServerErrorWithMsgResponse(http.StatusText(http.StatusInternalServerError))
 }
@@ -41,3 +53,56 @@ func getUnauthorizedErrorWithMsgResponse(msg string) errorResponse {
 		ErrorInfo: msg,
 	}
 }
+
+// tooManyRequestsResponse - 429 response with Retry-After header.
+type tooManyRequestsResponse struct {
+	errorResponse
+
+	retryAfter time.Duration
+}
+
+func (t tooManyRequestsResponse) writeJSON(w http.ResponseWriter) error {
+	seconds := int64(math.Ceil(t.retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+
+	return t.errorResponse.writeJSON(w)
+}
+
+func getTooManyRequestsResponse(msg string, retryAfter time.Duration) tooManyRequestsResponse {
+	return tooManyRequestsResponse{
+		errorResponse: errorResponse{
+			Status:    http.StatusTooManyRequests,
+			ErrorInfo: msg,
+		},
+		retryAfter: retryAfter,
+	}
+}
+
+// limitExceededResponse - 409 response for friends or pending requests limit.
+type limitExceededResponse struct {
+	errorResponse
+
+	Code  string `json:"code"`
+	Limit string `json:"limit"`
+	Max   int    `json:"max"`
+}
+
+func (l limitExceededResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, l.Status, l)
+}
+
+func getLimitExceededResponse(err *core.LimitError) limitExceededResponse {
+	return limitExceededResponse{
+		errorResponse: errorResponse{
+			Status:    http.StatusConflict,
+			ErrorInfo: err.Error(),
+		},
+		Code:  "friend_limit_exceeded",
+		Limit: err.Limit,
+		Max:   err.Max,
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// exportWriter - sets archive headers on first write, so errors before
+// any data is produced can still be reported as JSON.
+type exportWriter struct {
+	w        http.ResponseWriter
+	filename string
+	started  bool
+}
+
+func (e *exportWriter) Write(bb []byte) (int, error) {
+	if !e.started {
+		e.started = true
+
+		e.w.Header().Set("Content-Type", "application/zip")
+		e.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, e.filename))
+		e.w.WriteHeader(http.StatusOK)
+	}
+
+	return e.w.Write(bb)
+}
+
+func (h *HTTP) exportHandler(w http.ResponseWriter, r *http.Request) {
+	c, err := h.authenticate(r)
+	if err != nil {
+		getUnauthorizedErrorResponse().writeJSON(w)
+
+		return
+	}
+
+	ew := &exportWriter{
+		w:        w,
+		filename: "friends-export.zip",
+	}
+
+	err = h.service.Export(r.Context(), c.UID, ew)
+	if err != nil {
+		h.requestLogger(r).Errorw("Export.", "started", ew.started, "err", err)
+
+		if !ew.started {
+			getInternalServerErrorResponse().writeJSON(w)
+
+			return
+		}
+
+		// status is already sent, broken connection tells client archive is incomplete
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type setFavoriteRequest struct {
+	IsFavorite *bool `json:"is_favorite"`
+}
+
+func (r *setFavoriteRequest) validate() error {
+	if r.IsFavorite == nil {
+		return errors.New(`"is_favorite": must be provided`)
+	}
+
+	return nil
+}
+
+func (h *HTTP) setFavoriteHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getSetFavoriteResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getFavoritesHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getFavoritesResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getSetFavoriteResponse(r *http.Request) response {
+	if r.Body == http.NoBody {
+		return getBadRequestWithMsgResponse("no body")
+	}
+
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	req := &setFavoriteRequest{}
+
+	err = unmarshalReader(r.Body, req)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	err = req.validate()
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	ok, err := h.service.SetFavorite(r.Context(), c.UID, mux.Vars(r)["uid"], *req.IsFavorite)
+	if err != nil {
+		if ok {
+			return getBadRequestWithMsgResponse(err.Error())
+		}
+
+		h.requestLogger(r).Errorw("Set favorite.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return getEmptyOKResponse()
+}
+
+func (h *HTTP) getFavoritesResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	opts, err := parseFriendsListOptions(r)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	opts.FavoritesOnly = true
+
+	favorites, err := h.service.GetFriends(r.Context(), c.UID, opts)
+	if err != nil {
+		h.requestLogger(r).Errorw("Get favorites.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return convertCoreFriendsToResponse(favorites)
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/gorilla/mux"
+)
+
+type followRequest struct {
+	UserID string `json:"user_id"`
+}
+
+func (r *followRequest) validate() error {
+	if r.UserID == "" {
+		return fmt.Errorf(`"user_id": cannot be empty`)
+	}
+
+	return nil
+}
+
+// parseFollowsPage - read before RFC3339 time and limit from query.
+func parseFollowsPage(r *http.Request) (core.FollowsPage, error) {
+	page := core.FollowsPage{}
+	query := r.URL.Query()
+
+	var err error
+
+	if v := query.Get("before"); v != "" {
+		page.Before, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return page, errors.New(`"before": must be RFC3339 time`)
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		page.Limit, err = strconv.Atoi(v)
+		if err != nil || page.Limit <= 0 {
+			return page, errors.New(`"limit": must be positive integer`)
+		}
+	}
+
+	return page, nil
+}
+
+func (h *HTTP) followHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getFollowResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) unfollowHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getUnfollowResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) removeFollowerHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getRemoveFollowerResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getFollowersHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getFollowsResponse(r, "Get followers.", h.service.GetFollowers)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getFollowingHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getFollowsResponse(r, "Get following.", h.service.GetFollowing)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getFollowResponse(r *http.Request) response {
+	if r.Body == http.NoBody {
+		return getBadRequestWithMsgResponse("no body")
+	}
+
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	req := &followRequest{}
+
+	err = unmarshalReader(r.Body, req)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	err = req.validate()
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	if req.UserID == c.UID {
+		return getBadRequestWithMsgResponse("you cannot follow yourself")
+	}
+
+	ok, err := h.service.Follow(actorContext(r, c.UID), c.UID, req.UserID)
+	if err != nil {
+		if ok {
+			return getBadRequestWithMsgResponse(err.Error())
+		}
+
+		h.requestLogger(r).Errorw("Follow.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return getEmptyOKResponse()
+}
+
+func (h *HTTP) getUnfollowResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	ok, err := h.service.Unfollow(actorContext(r, c.UID), c.UID, mux.Vars(r)["uid"])
+	if err != nil {
+		if ok {
+			return getBadRequestWithMsgResponse(err.Error())
+		}
+
+		h.requestLogger(r).Errorw("Unfollow.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return getEmptyOKResponse()
+}
+
+func (h *HTTP) getRemoveFollowerResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	ok, err := h.service.RemoveFollower(actorContext(r, c.UID), c.UID, mux.Vars(r)["uid"])
+	if err != nil {
+		if ok {
+			return getBadRequestWithMsgResponse(err.Error())
+		}
+
+		h.requestLogger(r).Errorw("Remove follower.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return getEmptyOKResponse()
+}
+
+func (h *HTTP) getFollowsResponse(r *http.Request, logMsg string, get func(context.Context, string, core.FollowsPage) ([]*core.User, error)) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	page, err := parseFollowsPage(r)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	users, err := get(r.Context(), c.UID, page)
+	if err != nil {
+		h.requestLogger(r).Errorw(logMsg, "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return convertCoreUsersToResponse(users)
+}
@@ -1,15 +1,24 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 
-	"github.com/daniilty/sharenote-auth/claims"
+	"github.com/daniilty/sharenote-friends/internal/core"
 )
 
 type friend struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Since       *time.Time `json:"since,omitempty"`
+	RequestedAt *time.Time `json:"requested_at,omitempty"`
+	// IsFavorite - set in friends lists only.
+	IsFavorite *bool       `json:"is_favorite,omitempty"`
+	Annotation *annotation `json:"annotation,omitempty"`
 }
 
 type friendsResponse struct {
@@ -41,6 +50,66 @@ func (r *addFriendRequest) validate() error {
 	return nil
 }
 
+// parseListOptions - read list options from query, sort is one of name or recent.
+func parseListOptions(r *http.Request) (core.ListOptions, error) {
+	opts := core.ListOptions{
+		Sort: r.URL.Query().Get("sort"),
+	}
+
+	if !core.IsValidSort(opts.Sort) {
+		return opts, fmt.Errorf(`"sort": must be one of %q, %q`, core.SortName, core.SortRecent)
+	}
+
+	return opts, nil
+}
+
+// parseFriendsListOptions - read list options with friends search filters from query,
+// since_from and since_to are RFC3339 times.
+func parseFriendsListOptions(r *http.Request) (core.ListOptions, error) {
+	const maxQueryLen = 100
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return opts, err
+	}
+
+	query := r.URL.Query()
+
+	opts.Query = strings.TrimSpace(query.Get("q"))
+	if utf8.RuneCountInString(opts.Query) > maxQueryLen {
+		return opts, fmt.Errorf(`"q": must be at most %d characters`, maxQueryLen)
+	}
+
+	// friends service does not store groups, filter is rejected instead of ignored
+	if query.Get("group") != "" {
+		return opts, errors.New(`"group": groups are not supported`)
+	}
+
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{name: "since_from", dst: &opts.SinceFrom},
+		{name: "since_to", dst: &opts.SinceTo},
+	} {
+		v := query.Get(bound.name)
+		if v == "" {
+			continue
+		}
+
+		*bound.dst, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return opts, fmt.Errorf("%q: must be RFC3339 time", bound.name)
+		}
+	}
+
+	if !opts.SinceFrom.IsZero() && !opts.SinceTo.IsZero() && !opts.SinceFrom.Before(opts.SinceTo) {
+		return opts, errors.New(`"since_from": must be before "since_to"`)
+	}
+
+	return opts, nil
+}
+
 func (f *friendsResponse) writeJSON(w http.ResponseWriter) error {
 	return writeJSONResponse(w, http.StatusOK, f)
 }
@@ -70,30 +139,40 @@ func (h *HTTP) acceptFriendHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *HTTP) getFriendsResponse(r *http.Request) response {
-	c, err := claims.ParseHTTPHeader(r.Header)
+	c, err := h.authenticate(r)
 	if err != nil {
 		return getUnauthorizedErrorResponse()
 	}
 
-	friends, err := h.service.GetFriends(r.Context(), c.UID)
+	opts, err := parseFriendsListOptions(r)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	friends, err := h.service.GetFriends(r.Context(), c.UID, opts)
 	if err != nil {
-		h.logger.Errorw("Get Friends.", "err", err)
+		h.requestLogger(r).Errorw("Get Friends.", "err", err)
 
 		return getInternalServerErrorResponse()
 	}
 
-	return convertCoreUsersToResponse(friends)
+	return convertCoreFriendsToResponse(friends)
 }
 
 func (h *HTTP) getFriendRequestsResponse(r *http.Request) response {
-	c, err := claims.ParseHTTPHeader(r.Header)
+	c, err := h.authenticate(r)
 	if err != nil {
 		return getUnauthorizedErrorResponse()
 	}
 
-	friends, err := h.service.GetFriendRequests(r.Context(), c.UID)
+	opts, err := parseListOptions(r)
 	if err != nil {
-		h.logger.Errorw("Get Friend requests.", "err", err)
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	friends, err := h.service.GetFriendRequests(r.Context(), c.UID, opts)
+	if err != nil {
+		h.requestLogger(r).Errorw("Get Friend requests.", "err", err)
 
 		return getInternalServerErrorResponse()
 	}
@@ -106,7 +185,7 @@ func (h *HTTP) getRequestFriendResponse(r *http.Request) response {
 		return getBadRequestWithMsgResponse("no body")
 	}
 
-	c, err := claims.ParseHTTPHeader(r.Header)
+	c, err := h.authenticate(r)
 	if err != nil {
 		return getUnauthorizedErrorResponse()
 	}
@@ -127,13 +206,23 @@ func (h *HTTP) getRequestFriendResponse(r *http.Request) response {
 		return getBadRequestWithMsgResponse("you cannot be friend with yourself")
 	}
 
-	ok, err := h.service.RequestFriend(r.Context(), c.UID, req.FriendID)
+	ok, err := h.service.RequestFriend(actorContext(r, c.UID), c.UID, req.FriendID)
 	if err != nil {
+		var rateLimitErr *core.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			return getTooManyRequestsResponse(rateLimitErr.Reason, rateLimitErr.RetryAfter)
+		}
+
+		var limitErr *core.LimitError
+		if errors.As(err, &limitErr) {
+			return getLimitExceededResponse(limitErr)
+		}
+
 		if ok {
 			return getBadRequestWithMsgResponse(err.Error())
 		}
 
-		h.logger.Errorw("Request Friend.", "err", err)
+		h.requestLogger(r).Errorw("Request Friend.", "err", err)
 
 		return getInternalServerErrorResponse()
 	}
@@ -146,7 +235,7 @@ func (h *HTTP) getAcceptFriendResponse(r *http.Request) response {
 		return getBadRequestWithMsgResponse("no body")
 	}
 
-	c, err := claims.ParseHTTPHeader(r.Header)
+	c, err := h.authenticate(r)
 	if err != nil {
 		return getUnauthorizedErrorResponse()
 	}
@@ -163,13 +252,18 @@ func (h *HTTP) getAcceptFriendResponse(r *http.Request) response {
 		return getBadRequestWithMsgResponse(err.Error())
 	}
 
-	ok, err := h.service.AddFriend(r.Context(), req.FriendID, c.UID)
+	ok, err := h.service.AddFriend(actorContext(r, c.UID), req.FriendID, c.UID)
 	if err != nil {
+		var limitErr *core.LimitError
+		if errors.As(err, &limitErr) {
+			return getLimitExceededResponse(limitErr)
+		}
+
 		if ok {
 			return getBadRequestWithMsgResponse(err.Error())
 		}
 
-		h.logger.Errorw("Add friend.", "err", err)
+		h.requestLogger(r).Errorw("Add friend.", "err", err)
 
 		return getInternalServerErrorResponse()
 	}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+)
+
+const (
+	healthStatusOK       = "ok"
+	healthStatusError    = "error"
+	healthStatusReady    = "ready"
+	healthStatusNotReady = "not_ready"
+)
+
+type dependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type readinessResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]*dependencyStatus `json:"checks"`
+
+	code int
+}
+
+func (r *readinessResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, r.code, r)
+}
+
+type livenessResponse struct {
+	Status string `json:"status"`
+}
+
+func (l *livenessResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, http.StatusOK, l)
+}
+
+func (h *HTTP) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	resp := &livenessResponse{
+		Status: healthStatusOK,
+	}
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getReadinessResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getReadinessResponse(r *http.Request) response {
+	ready, results := h.readiness.Check(r.Context())
+
+	resp := &readinessResponse{
+		Status: healthStatusReady,
+		Checks: make(map[string]*dependencyStatus, len(results)),
+		code:   http.StatusOK,
+	}
+
+	if !ready {
+		resp.Status = healthStatusNotReady
+		resp.code = http.StatusServiceUnavailable
+	}
+
+	for _, res := range results {
+		status := &dependencyStatus{
+			Status: healthStatusOK,
+		}
+
+		if res.Err != nil {
+			status.Status = healthStatusError
+			status.Error = res.Err.Error()
+		}
+
+		resp.Checks[res.Name] = status
+	}
+
+	return resp
+}
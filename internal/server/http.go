@@ -4,19 +4,43 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/daniilty/sharenote-friends/internal/auth"
 	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/daniilty/sharenote-friends/internal/health"
 	"github.com/gorilla/mux"
 )
 
+// HTTPConfig - http server config.
+type HTTPConfig struct {
+	Addr string
+	// ReadTimeout - time to read whole request including body.
+	ReadTimeout time.Duration
+	// WriteTimeout - time from end of request headers read to end of response write.
+	WriteTimeout time.Duration
+	// IdleTimeout - keep-alive connection idle time.
+	IdleTimeout time.Duration
+	// RequestTimeout - default route handler timeout, must be less than WriteTimeout.
+	RequestTimeout time.Duration
+	// ExportTimeout - export handler timeout, must be less than WriteTimeout.
+	ExportTimeout time.Duration
+	// MaxBodyBytes - default route request body limit.
+	MaxBodyBytes int64
+}
+
 // HTTP - http server.
 type HTTP struct {
 	innerServer *http.Server
 
-	logger  *zap.SugaredLogger
-	service core.Service
+	logger        *zap.SugaredLogger
+	service       core.Service
+	authenticator auth.Authenticator
+	readiness     *health.Readiness
+	defaultLimits routeLimits
+	streamLimits  routeLimits
 }
 
 func (h *HTTP) Run(ctx context.Context) {
@@ -35,23 +59,42 @@ func (h *HTTP) Run(ctx context.Context) {
 
 	<-ctx.Done()
 
+	h.logger.Info("Draining before graceful server shutdown.")
+	h.readiness.Drain()
+
 	h.logger.Info("Graceful server shutdown.")
 	h.innerServer.Shutdown(context.Background())
 }
 
 // NewHTTP - constructor.
-func NewHTTP(addr string, logger *zap.SugaredLogger, service core.Service) *HTTP {
+func NewHTTP(cfg HTTPConfig, logger *zap.SugaredLogger, service core.Service, authenticator auth.Authenticator, readiness *health.Readiness) *HTTP {
 	h := &HTTP{
-		logger:  logger,
-		service: service,
+		logger:        logger,
+		service:       service,
+		authenticator: authenticator,
+		readiness:     readiness,
+		defaultLimits: routeLimits{
+			timeout:      cfg.RequestTimeout,
+			maxBodyBytes: cfg.MaxBodyBytes,
+		},
+		streamLimits: routeLimits{
+			timeout:      cfg.ExportTimeout,
+			maxBodyBytes: cfg.MaxBodyBytes,
+			streaming:    true,
+		},
 	}
 
 	r := mux.NewRouter()
+	r.Use(h.tracingMiddleware, h.metricsMiddleware)
 	h.setRoutes(r)
 
 	srv := &http.Server{
-		Addr:    addr,
-		Handler: r,
+		Addr:              cfg.Addr,
+		Handler:           h.requestIDMiddleware(h.accessLogMiddleware(h.recoverMiddleware(r))),
+		ReadHeaderTimeout: cfg.ReadTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
 	}
 
 	h.innerServer = srv
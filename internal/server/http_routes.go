@@ -10,23 +10,142 @@ func (h *HTTP) setRoutes(r *mux.Router) {
 	const (
 		requestsPath       = "/requests"
 		acceptRequestsPath = requestsPath + "/accept"
+		acceptBatchPath    = requestsPath + "/accept:batch"
+		declineBatchPath   = requestsPath + "/decline:batch"
+		exportPath         = "/export"
+		invitesPath        = "/invites"
+		invitePath         = invitesPath + "/{id}"
+		redeemInvitePath   = invitesPath + "/{token}/redeem"
+		followingPath      = "/following"
+		followedPath       = followingPath + "/{uid}"
+		followersPath      = "/followers"
+		followerPath       = followersPath + "/{uid}"
+		blocksPath         = "/blocks"
+		blockedPath        = blocksPath + "/{uid}"
+		favoritesPath      = "/favorites"
+		favoritePath       = "/{uid}/favorite"
+		annotationPath     = "/{uid}/annotation"
+		countsPath         = "/counts"
+		countsBatchPath    = countsPath + ":batch"
+		pathPath           = "/path/{uid}"
+		settingsPath       = "/settings"
 	)
 
+	r.HandleFunc("/healthz",
+		h.healthzHandler,
+	).Methods(http.MethodGet)
+
+	r.HandleFunc("/readyz",
+		h.readyzHandler,
+	).Methods(http.MethodGet)
+
 	api := r.PathPrefix("/api/v1/friends").Subrouter()
 
-	api.HandleFunc("",
-		h.getFriendsHandler,
+	api.Handle("",
+		h.limit(h.defaultLimits, h.getFriendsHandler),
+	).Methods(http.MethodGet)
+
+	api.Handle(requestsPath,
+		h.limit(h.defaultLimits, h.getFriendRequestsHandler),
 	).Methods(http.MethodGet)
 
-	api.HandleFunc(requestsPath,
-		h.getFriendRequestsHandler,
+	api.Handle(requestsPath,
+		h.limit(h.defaultLimits, h.requestFriendHandler),
+	).Methods(http.MethodPost)
+
+	api.Handle(acceptRequestsPath,
+		h.limit(h.defaultLimits, h.acceptFriendHandler),
+	).Methods(http.MethodPost)
+
+	api.Handle(acceptBatchPath,
+		h.limit(h.defaultLimits, h.acceptFriendsBatchHandler),
+	).Methods(http.MethodPost)
+
+	api.Handle(declineBatchPath,
+		h.limit(h.defaultLimits, h.declineFriendsBatchHandler),
+	).Methods(http.MethodPost)
+
+	api.Handle(invitesPath,
+		h.limit(h.defaultLimits, h.createInviteHandler),
+	).Methods(http.MethodPost)
+
+	api.Handle(invitesPath,
+		h.limit(h.defaultLimits, h.getInvitesHandler),
 	).Methods(http.MethodGet)
 
-	api.HandleFunc(requestsPath,
-		h.requestFriendHandler,
+	api.Handle(invitePath,
+		h.limit(h.defaultLimits, h.revokeInviteHandler),
+	).Methods(http.MethodDelete)
+
+	api.Handle(redeemInvitePath,
+		h.limit(h.defaultLimits, h.redeemInviteHandler),
 	).Methods(http.MethodPost)
 
-	api.HandleFunc(acceptRequestsPath,
-		h.acceptFriendHandler,
+	api.Handle(followingPath,
+		h.limit(h.defaultLimits, h.getFollowingHandler),
+	).Methods(http.MethodGet)
+
+	api.Handle(followingPath,
+		h.limit(h.defaultLimits, h.followHandler),
 	).Methods(http.MethodPost)
+
+	api.Handle(followedPath,
+		h.limit(h.defaultLimits, h.unfollowHandler),
+	).Methods(http.MethodDelete)
+
+	api.Handle(followersPath,
+		h.limit(h.defaultLimits, h.getFollowersHandler),
+	).Methods(http.MethodGet)
+
+	api.Handle(followerPath,
+		h.limit(h.defaultLimits, h.removeFollowerHandler),
+	).Methods(http.MethodDelete)
+
+	api.Handle(blocksPath,
+		h.limit(h.defaultLimits, h.getBlockedHandler),
+	).Methods(http.MethodGet)
+
+	api.Handle(blocksPath,
+		h.limit(h.defaultLimits, h.blockHandler),
+	).Methods(http.MethodPost)
+
+	api.Handle(blockedPath,
+		h.limit(h.defaultLimits, h.unblockHandler),
+	).Methods(http.MethodDelete)
+
+	api.Handle(favoritesPath,
+		h.limit(h.defaultLimits, h.getFavoritesHandler),
+	).Methods(http.MethodGet)
+
+	api.Handle(favoritePath,
+		h.limit(h.defaultLimits, h.setFavoriteHandler),
+	).Methods(http.MethodPut)
+
+	api.Handle(annotationPath,
+		h.limit(h.defaultLimits, h.setAnnotationHandler),
+	).Methods(http.MethodPut)
+
+	api.Handle(countsPath,
+		h.limit(h.defaultLimits, h.getCountsHandler),
+	).Methods(http.MethodGet)
+
+	api.Handle(countsBatchPath,
+		h.limit(h.defaultLimits, h.getCountsBatchHandler),
+	).Methods(http.MethodPost)
+
+	api.Handle(pathPath,
+		h.limit(h.defaultLimits, h.getPathHandler),
+	).Methods(http.MethodGet)
+
+	api.Handle(settingsPath,
+		h.limit(h.defaultLimits, h.getSettingsHandler),
+	).Methods(http.MethodGet)
+
+	api.Handle(settingsPath,
+		h.limit(h.defaultLimits, h.setSettingsHandler),
+	).Methods(http.MethodPut)
+
+	api.Handle(exportPath,
+		h.limit(h.streamLimits, h.exportHandler),
+	).Methods(http.MethodGet)
 }
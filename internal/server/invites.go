@@ -0,0 +1,226 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/core"
+	"github.com/gorilla/mux"
+)
+
+type createInviteRequest struct {
+	Mode       string `json:"mode"`
+	SingleUse  bool   `json:"single_use"`
+	TTLSeconds int64  `json:"ttl_seconds"`
+}
+
+func (r *createInviteRequest) validate() error {
+	if r.Mode == "" {
+		return fmt.Errorf(`"mode": cannot be empty`)
+	}
+
+	if r.TTLSeconds < 0 {
+		return fmt.Errorf(`"ttl_seconds": cannot be negative`)
+	}
+
+	return nil
+}
+
+type invite struct {
+	ID        string    `json:"id"`
+	Token     string    `json:"token"`
+	Mode      string    `json:"mode"`
+	SingleUse bool      `json:"single_use"`
+	Redeemed  int       `json:"redeemed"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type inviteResponse struct {
+	Status string  `json:"status"`
+	Data   *invite `json:"data"`
+}
+
+func (i *inviteResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, http.StatusOK, i)
+}
+
+type invitesResponse struct {
+	Status string    `json:"status"`
+	Data   []*invite `json:"data"`
+}
+
+func (i *invitesResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, http.StatusOK, i)
+}
+
+type redeemedInvite struct {
+	InviterID string `json:"inviter_id"`
+	Mode      string `json:"mode"`
+}
+
+type redeemInviteResponse struct {
+	Status string          `json:"status"`
+	Data   *redeemedInvite `json:"data"`
+}
+
+func (r *redeemInviteResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, http.StatusOK, r)
+}
+
+func (h *HTTP) createInviteHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getCreateInviteResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getInvitesHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getInvitesResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) revokeInviteHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getRevokeInviteResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) redeemInviteHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getRedeemInviteResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getCreateInviteResponse(r *http.Request) response {
+	if r.Body == http.NoBody {
+		return getBadRequestWithMsgResponse("no body")
+	}
+
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	req := &createInviteRequest{}
+
+	err = unmarshalReader(r.Body, req)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	err = req.validate()
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	created, ok, err := h.service.CreateInvite(r.Context(), c.UID, core.InviteOptions{
+		Mode:      req.Mode,
+		SingleUse: req.SingleUse,
+		TTL:       time.Duration(req.TTLSeconds) * time.Second,
+	})
+	if err != nil {
+		if ok {
+			return getBadRequestWithMsgResponse(err.Error())
+		}
+
+		h.requestLogger(r).Errorw("Create invite.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return &inviteResponse{
+		Status: http.StatusText(http.StatusOK),
+		Data:   convertCoreInviteToResponse(created),
+	}
+}
+
+func (h *HTTP) getInvitesResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	invites, err := h.service.GetInvites(r.Context(), c.UID)
+	if err != nil {
+		h.requestLogger(r).Errorw("Get invites.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	data := make([]*invite, 0, len(invites))
+
+	for _, i := range invites {
+		data = append(data, convertCoreInviteToResponse(i))
+	}
+
+	return &invitesResponse{
+		Status: http.StatusText(http.StatusOK),
+		Data:   data,
+	}
+}
+
+func (h *HTTP) getRevokeInviteResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	ok, err := h.service.RevokeInvite(r.Context(), c.UID, mux.Vars(r)["id"])
+	if err != nil {
+		if ok {
+			return getBadRequestWithMsgResponse(err.Error())
+		}
+
+		h.requestLogger(r).Errorw("Revoke invite.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return getEmptyOKResponse()
+}
+
+func (h *HTTP) getRedeemInviteResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	redeemed, ok, err := h.service.RedeemInvite(actorContext(r, c.UID), c.UID, mux.Vars(r)["token"])
+	if err != nil {
+		var limitErr *core.LimitError
+		if errors.As(err, &limitErr) {
+			return getLimitExceededResponse(limitErr)
+		}
+
+		if ok {
+			return getBadRequestWithMsgResponse(err.Error())
+		}
+
+		h.requestLogger(r).Errorw("Redeem invite.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return &redeemInviteResponse{
+		Status: http.StatusText(http.StatusOK),
+		Data: &redeemedInvite{
+			InviterID: redeemed.Inviter,
+			Mode:      redeemed.Mode,
+		},
+	}
+}
+
+func convertCoreInviteToResponse(i *core.Invite) *invite {
+	return &invite{
+		ID:        i.ID,
+		Token:     i.Token,
+		Mode:      i.Mode,
+		SingleUse: i.SingleUse,
+		Redeemed:  i.Redeemed,
+		CreatedAt: i.CreatedAt,
+		ExpiresAt: i.ExpiresAt,
+	}
+}
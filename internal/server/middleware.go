@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+type requestIDKey struct{}
+
+type loggerKey struct{}
+
+// routeLimits - per route request timeout and body size limit.
+type routeLimits struct {
+	timeout      time.Duration
+	maxBodyBytes int64
+	// streaming - response is written while produced, must not be buffered.
+	streaming bool
+}
+
+// requestIDMiddleware - propagate or generate request id, put it and request logger into context.
+func (h *HTTP) requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		ctx = context.WithValue(ctx, loggerKey{}, h.logger.With("request_id", id))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (h *HTTP) accessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		h.requestLogger(r).Infow("HTTP request.",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration", time.Since(start),
+			"remote_addr", r.RemoteAddr,
+			"user_agent", r.UserAgent(),
+		)
+	})
+}
+
+// recoverMiddleware - turn handler panic into internal server error response.
+// Routes are also wrapped by limit, this one covers the rest of handler chain.
+func (h *HTTP) recoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			h.requestLogger(r).Errorw("Handler panic.", "panic", p, "stack", string(debug.Stack()))
+
+			if !rec.wroteHeader {
+				getInternalServerErrorResponse().writeJSON(rec)
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// limit - apply route timeout and request body size limit.
+// Streaming routes get context deadline instead of buffering timeout handler.
+// Panics are recovered inside timeout handler, it runs handler in own goroutine
+// and would re-panic with its own stack.
+func (h *HTTP) limit(limits routeLimits, handler http.HandlerFunc) http.Handler {
+	timeoutBody, _ := json.Marshal(errorResponse{
+		Status:    http.StatusServiceUnavailable,
+		ErrorInfo: "request timeout",
+	})
+
+	bodyLimited := h.recoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = http.MaxBytesReader(w, r.Body, limits.maxBodyBytes)
+		}
+
+		handler(w, r)
+	}))
+
+	if limits.streaming {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), limits.timeout)
+			defer cancel()
+
+			bodyLimited.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+
+	return http.TimeoutHandler(bodyLimited, limits.timeout, string(timeoutBody))
+}
+
+// requestLogger - logger with request id, falls back to server logger.
+func (h *HTTP) requestLogger(r *http.Request) *zap.SugaredLogger {
+	logger, ok := r.Context().Value(loggerKey{}).(*zap.SugaredLogger)
+	if !ok {
+		return h.logger
+	}
+
+	return logger
+}
+
+func newRequestID() string {
+	bb := make([]byte, 16)
+	rand.Read(bb)
+
+	return hex.EncodeToString(bb)
+}
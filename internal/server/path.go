@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type path struct {
+	// Degree - friendships between users, 1 for friends.
+	Degree int       `json:"degree"`
+	Users  []*friend `json:"users"`
+}
+
+type pathResponse struct {
+	Status string `json:"status"`
+	Data   *path  `json:"data"`
+}
+
+func (p *pathResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, http.StatusOK, p)
+}
+
+func (h *HTTP) getPathHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getPathResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getPathResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	target := mux.Vars(r)["uid"]
+	if target == c.UID {
+		return getBadRequestWithMsgResponse("path to yourself is empty")
+	}
+
+	uu, err := h.service.FindPath(r.Context(), c.UID, target)
+	if err != nil {
+		h.requestLogger(r).Errorw("Find path.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	if uu == nil {
+		return getNotFoundWithMsgResponse("users are not connected within search limits")
+	}
+
+	users := make([]*friend, 0, len(uu))
+
+	for _, u := range uu {
+		users = append(users, convertCoreUserToResponse(u))
+	}
+
+	return &pathResponse{
+		Status: http.StatusText(http.StatusOK),
+		Data: &path{
+			Degree: len(users) - 1,
+			Users:  users,
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/daniilty/sharenote-friends/internal/core"
+)
+
+type settings struct {
+	HideConnections bool `json:"hide_connections"`
+}
+
+type setSettingsRequest struct {
+	HideConnections *bool `json:"hide_connections"`
+}
+
+func (r *setSettingsRequest) validate() error {
+	if r.HideConnections == nil {
+		return errors.New(`"hide_connections": must be provided`)
+	}
+
+	return nil
+}
+
+type settingsResponse struct {
+	Status string    `json:"status"`
+	Data   *settings `json:"data"`
+}
+
+func (s *settingsResponse) writeJSON(w http.ResponseWriter) error {
+	return writeJSONResponse(w, http.StatusOK, s)
+}
+
+func (h *HTTP) getSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getSettingsResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) setSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.getSetSettingsResponse(r)
+
+	resp.writeJSON(w)
+}
+
+func (h *HTTP) getSettingsResponse(r *http.Request) response {
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	s, err := h.service.GetSettings(r.Context(), c.UID)
+	if err != nil {
+		h.requestLogger(r).Errorw("Get settings.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return &settingsResponse{
+		Status: http.StatusText(http.StatusOK),
+		Data: &settings{
+			HideConnections: s.HideConnections,
+		},
+	}
+}
+
+func (h *HTTP) getSetSettingsResponse(r *http.Request) response {
+	if r.Body == http.NoBody {
+		return getBadRequestWithMsgResponse("no body")
+	}
+
+	c, err := h.authenticate(r)
+	if err != nil {
+		return getUnauthorizedErrorResponse()
+	}
+
+	req := &setSettingsRequest{}
+
+	err = unmarshalReader(r.Body, req)
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	err = req.validate()
+	if err != nil {
+		return getBadRequestWithMsgResponse(err.Error())
+	}
+
+	err = h.service.SetSettings(r.Context(), c.UID, core.Settings{HideConnections: *req.HideConnections})
+	if err != nil {
+		h.requestLogger(r).Errorw("Set settings.", "err", err)
+
+		return getInternalServerErrorResponse()
+	}
+
+	return getEmptyOKResponse()
+}
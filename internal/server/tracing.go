@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingMiddleware - start server span continuing propagated trace context,
+// span is named by route template.
+func (h *HTTP) tracingMiddleware(next http.Handler) http.Handler {
+	tagged := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trace.SpanFromContext(r.Context()).SetAttributes(semconv.HTTPRouteKey.String(routeTemplate(r, r.URL.Path)))
+
+		next.ServeHTTP(w, r)
+	})
+
+	return otelhttp.NewHandler(tagged, "http",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + routeTemplate(r, r.URL.Path)
+		}),
+	)
+}
+
+// routeTemplate - path template of matched route, fallback when there is none.
+func routeTemplate(r *http.Request, fallback string) string {
+	current := mux.CurrentRoute(r)
+	if current == nil {
+		return fallback
+	}
+
+	tpl, err := current.GetPathTemplate()
+	if err != nil {
+		return fallback
+	}
+
+	return tpl
+}
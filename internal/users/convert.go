@@ -1,18 +1,20 @@
 package users
 
 import (
-	"fmt"
+	"errors"
 
 	events "github.com/daniilty/sharenote-kafka-events"
 )
 
+var errInvalidEventData = errors.New("invalid data format")
+
 func eventDataToUserDeleteEventData(data map[string]interface{}) (*events.UserDeleteEvent, error) {
 	var ok bool
 	u := &events.UserDeleteEvent{}
 
 	u.ID, ok = data["id"].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid data format")
+		return nil, errInvalidEventData
 	}
 
 	return u, nil
@@ -2,16 +2,33 @@ package users
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 	"time"
 
+	"github.com/daniilty/sharenote-friends/internal/audit"
 	"github.com/daniilty/sharenote-friends/internal/kafka"
 	"github.com/daniilty/sharenote-friends/internal/mongo"
+	"github.com/daniilty/sharenote-friends/internal/usersclient"
 	events "github.com/daniilty/sharenote-kafka-events"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+// eventsActorID - audit actor of changes caused by users events.
+const eventsActorID = "users_service"
+
+// tracerName - instrumentation name of users events spans.
+const tracerName = "github.com/daniilty/sharenote-friends/internal/users"
+
 type EventsHandler interface {
 	Listen(ctx context.Context)
+	// Listening - handler is consuming events.
+	Listening() bool
 }
 
 type EventsHandlerImpl struct {
@@ -19,19 +36,31 @@ type EventsHandlerImpl struct {
 	timeout       time.Duration
 	db            mongo.DB
 	kafkaConsumer kafka.Consumer
+	usersCache    usersclient.Invalidator
+	listening     int32
+}
+
+// NewEventsHandler - EventsHandler constructor, usersCache is optional.
+func NewEventsHandler(logger *zap.SugaredLogger, timeout time.Duration, db mongo.DB, consumer kafka.Consumer, usersCache usersclient.Invalidator) EventsHandler {
+	return newEventsHandlerImpl(logger, timeout, db, consumer, usersCache)
 }
 
-func NewEventsHandler(logger *zap.SugaredLogger, timeout time.Duration, db mongo.DB, consumer kafka.Consumer) EventsHandler {
+func newEventsHandlerImpl(logger *zap.SugaredLogger, timeout time.Duration, db mongo.DB, consumer kafka.Consumer, usersCache usersclient.Invalidator) *EventsHandlerImpl {
 	return &EventsHandlerImpl{
 		logger:        logger,
 		timeout:       timeout,
 		db:            db,
 		kafkaConsumer: consumer,
+		usersCache:    usersCache,
 	}
 }
 
 func (e *EventsHandlerImpl) Listen(ctx context.Context) {
 	e.logger.Info("Listening for user events.")
+
+	atomic.StoreInt32(&e.listening, 1)
+	defer atomic.StoreInt32(&e.listening, 0)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -44,28 +73,82 @@ func (e *EventsHandlerImpl) Listen(ctx context.Context) {
 	}
 }
 
+func (e *EventsHandlerImpl) Listening() bool {
+	return atomic.LoadInt32(&e.listening) == 1
+}
+
 func (e *EventsHandlerImpl) handleMessage(ctx context.Context) {
 	event := &events.Event{}
 
-	commit, err := e.kafkaConsumer.UnmarshalMessage(ctx, event)
+	commit, headers, err := e.kafkaConsumer.UnmarshalMessage(ctx, event)
 	if err != nil {
+		if ctx.Err() == nil {
+			kafkaMessagesFailedTotal.WithLabelValues(eventTypeUnknown).Inc()
+		}
+
 		e.logger.Errorw("Unmarshal kafka message.", "err", err)
 
 		return
 	}
 
+	// producer trace is linked, consumer span starts own trace
+	producer := trace.SpanContextFromContext(otel.GetTextMapPropagator().Extract(ctx, headers))
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "users.events process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(trace.Link{SpanContext: producer}),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("kafka"),
+			semconv.MessagingOperationProcess,
+			attribute.String("event.type", event.Type),
+		),
+	)
+	defer span.End()
+
+	kafkaConsumerLag.Set(float64(e.kafkaConsumer.Lag()))
+
+	applyCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	err = e.applyEvent(applyCtx, event)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		kafkaMessagesFailedTotal.WithLabelValues(event.Type).Inc()
+
+		if !errors.Is(err, errInvalidEventData) {
+			return
+		}
+	} else {
+		kafkaMessagesProcessedTotal.WithLabelValues(event.Type).Inc()
+	}
+
+	err = commit(ctx)
+	if err != nil {
+		e.logger.Errorw("Commit kafka message.", "err", err)
+
+		return
+	}
+
+	kafkaMessagesCommittedTotal.Inc()
+}
+
+func (e *EventsHandlerImpl) applyEvent(ctx context.Context, event *events.Event) error {
+	// replay keeps operator as actor
+	if _, ok := audit.ActorFromContext(ctx); !ok {
+		ctx = audit.WithActor(ctx, audit.Actor{
+			ID:     eventsActorID,
+			Source: audit.SourceKafka,
+		})
+	}
+
 	switch event.Type {
 	case events.EventTypeUserDelete:
 		userDeleteEvent, err := eventDataToUserDeleteEventData(event.Data)
 		if err != nil {
 			e.logger.Errorw("Convert event data to delete user event.", "err", err)
 
-			err = commit(ctx)
-			if err != nil {
-				e.logger.Errorw("Commit kafka message.", "err", err)
-			}
-
-			return
+			return err
 		}
 
 		e.logger.Infow("Remove user event.", "id", userDeleteEvent.ID)
@@ -74,12 +157,22 @@ func (e *EventsHandlerImpl) handleMessage(ctx context.Context) {
 		if err != nil {
 			e.logger.Errorw("Remove user.", "err", err)
 
-			return
+			return err
 		}
+
+		e.invalidateUser(ctx, userDeleteEvent.ID)
 	}
 
-	err = commit(ctx)
+	return nil
+}
+
+func (e *EventsHandlerImpl) invalidateUser(ctx context.Context, id string) {
+	if e.usersCache == nil {
+		return
+	}
+
+	err := e.usersCache.Invalidate(ctx, id)
 	if err != nil {
-		e.logger.Errorw("Commit kafka message.", "err", err)
+		e.logger.Errorw("Invalidate cached user.", "id", id, "err", err)
 	}
 }
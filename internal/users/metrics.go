@@ -0,0 +1,28 @@
+package users
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// eventTypeUnknown - label for messages that could not be unmarshalled.
+const eventTypeUnknown = "unknown"
+
+var (
+	kafkaMessagesProcessedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "friends_kafka_messages_processed_total",
+		Help: "Users events handled successfully by event type.",
+	}, []string{"event_type"})
+	kafkaMessagesFailedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "friends_kafka_messages_failed_total",
+		Help: "Users events failed to unmarshal or handle by event type.",
+	}, []string{"event_type"})
+	kafkaMessagesCommittedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "friends_kafka_messages_committed_total",
+		Help: "Users events offsets committed.",
+	})
+	kafkaConsumerLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "friends_kafka_consumer_lag",
+		Help: "Users events consumer lag in messages.",
+	})
+)
@@ -0,0 +1,134 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/daniilty/sharenote-friends/internal/kafka"
+	"github.com/daniilty/sharenote-friends/internal/mongo"
+	events "github.com/daniilty/sharenote-kafka-events"
+	"go.uber.org/zap"
+)
+
+// Replayer - reprocess users events history.
+type Replayer interface {
+	// Replay - read events until consumer is drained, report changes to writer
+	// and apply them unless dry run is set.
+	Replay(ctx context.Context, w io.Writer, dryRun bool) (*ReplayStats, error)
+}
+
+// ReplayStats - replay summary.
+type ReplayStats struct {
+	Processed int
+	Applied   int
+	Skipped   int
+	Failed    int
+}
+
+// NewReplayer - replayer running messages through events handler logic.
+func NewReplayer(logger *zap.SugaredLogger, timeout time.Duration, db mongo.DB, consumer kafka.Consumer) Replayer {
+	return newEventsHandlerImpl(logger, timeout, db, consumer, nil)
+}
+
+func (e *EventsHandlerImpl) Replay(ctx context.Context, w io.Writer, dryRun bool) (*ReplayStats, error) {
+	stats := &ReplayStats{}
+
+	for {
+		event := &events.Event{}
+
+		commit, _, err := e.kafkaConsumer.UnmarshalMessage(ctx, event)
+		switch {
+		case errors.Is(err, kafka.ErrReplayDone):
+			return stats, nil
+		case errors.Is(err, kafka.ErrMalformedMessage):
+			stats.Failed++
+			fmt.Fprintf(w, "skip %s\n", err)
+
+			if !dryRun {
+				err = commit(ctx)
+				if err != nil {
+					return stats, fmt.Errorf("commit offset: %w", err)
+				}
+			}
+
+			continue
+		case err != nil:
+			// broker, network and context errors stop replay, offset is kept for rerun
+			return stats, fmt.Errorf("fetch message: %w", err)
+		}
+
+		stats.Processed++
+
+		// each event is handled within timeout, as in live handler
+		eventCtx, cancel := context.WithTimeout(ctx, e.timeout)
+
+		change, err := e.describeEvent(eventCtx, event)
+		switch {
+		case errors.Is(err, errInvalidEventData):
+			stats.Failed++
+			fmt.Fprintf(w, "skip %s event: %s\n", event.Type, err)
+		case err != nil:
+			cancel()
+
+			return stats, fmt.Errorf("describe %s event: %w", event.Type, err)
+		case change == "":
+			stats.Skipped++
+		default:
+			fmt.Fprintln(w, change)
+		}
+
+		if dryRun {
+			cancel()
+
+			continue
+		}
+
+		// applying is idempotent, references missed by description are still removed
+		err = e.applyEvent(eventCtx, event)
+		cancel()
+
+		switch {
+		case errors.Is(err, errInvalidEventData):
+			// already counted as failed by description
+		case err != nil:
+			return stats, fmt.Errorf("apply %s event: %w", event.Type, err)
+		case change != "":
+			stats.Applied++
+		}
+
+		err = commit(ctx)
+		if err != nil {
+			return stats, fmt.Errorf("commit offset: %w", err)
+		}
+	}
+}
+
+// describeEvent - describe what applying event would change, empty result means no-op.
+func (e *EventsHandlerImpl) describeEvent(ctx context.Context, event *events.Event) (string, error) {
+	switch event.Type {
+	case events.EventTypeUserDelete:
+		userDeleteEvent, err := eventDataToUserDeleteEventData(event.Data)
+		if err != nil {
+			return "", err
+		}
+
+		refs, err := e.db.GetUserReferences(ctx, userDeleteEvent.ID)
+		if err != nil {
+			return "", fmt.Errorf("get user references: %w", err)
+		}
+
+		if refs.IsEmpty() {
+			return "", nil
+		}
+
+		return fmt.Sprintf("%s %s: remove %d friends, %d friend of, %d incoming requests, %d outgoing requests, "+
+			"%d following, %d followers, %d blocks, %d invites, %d limits overrides, %d counters, %d settings",
+			event.Type, userDeleteEvent.ID, refs.Friends, refs.FriendOf, refs.IncomingRequests, refs.OutgoingRequests,
+			refs.Following, refs.Followers, refs.Blocks, refs.Invites, refs.LimitsOverride, refs.Counts, refs.Settings), nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,16 @@
+package usersclient
+
+import (
+	"math/rand"
+	"time"
+)
+
+// backoff - full jitter exponential backoff for attempt starting from zero.
+func backoff(attempt int, base time.Duration, max time.Duration) time.Duration {
+	d := base << attempt
+	if d <= 0 || d > max {
+		d = max
+	}
+
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
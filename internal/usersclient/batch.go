@@ -0,0 +1,128 @@
+package usersclient
+
+import (
+	"context"
+	"sync"
+
+	schema "github.com/daniilty/sharenote-grpc-schema"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+var _ schema.UsersClient = (*BatchedClient)(nil)
+
+// BatchedClient - users client decorator splitting GetUsers into concurrent batches.
+type BatchedClient struct {
+	schema.UsersClient
+
+	logger      *zap.SugaredLogger
+	batchSize   int
+	concurrency int
+}
+
+// NewBatchedClient - BatchedClient constructor.
+func NewBatchedClient(client schema.UsersClient, logger *zap.SugaredLogger, batchSize int, concurrency int) *BatchedClient {
+	return &BatchedClient{
+		UsersClient: client,
+		logger:      logger,
+		batchSize:   batchSize,
+		concurrency: concurrency,
+	}
+}
+
+// GetUsers - get users in batches, result keeps requested ids order.
+// Ids unknown to users service are logged so stale references can be cleaned up.
+func (b *BatchedClient) GetUsers(ctx context.Context, in *schema.GetUsersRequest, opts ...grpc.CallOption) (*schema.GetUsersResponse, error) {
+	ids := in.GetIds()
+	batches := splitIDs(ids, b.batchSize)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]*schema.User, len(batches))
+	sem := make(chan struct{}, b.concurrency)
+	wg := &sync.WaitGroup{}
+
+	// first failure cancels other batches, their context errors must not hide it
+	var (
+		firstErr error
+		failOnce sync.Once
+	)
+
+	for i := range batches {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			resp, err := b.UsersClient.GetUsers(ctx, &schema.GetUsersRequest{
+				Ids: batches[i],
+			}, opts...)
+			if err != nil {
+				failOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+
+				return
+			}
+
+			results[i] = resp.GetUsers()
+		}(i)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	byID := make(map[string]*schema.User, len(ids))
+
+	for i := range results {
+		for _, u := range results[i] {
+			byID[u.GetId()] = u
+		}
+	}
+
+	users := make([]*schema.User, 0, len(ids))
+	missing := []string{}
+
+	for i := range ids {
+		u, ok := byID[ids[i]]
+		if !ok {
+			missing = append(missing, ids[i])
+
+			continue
+		}
+
+		users = append(users, u)
+	}
+
+	if len(missing) > 0 {
+		b.logger.Warnw("Users not found in users service.", "ids", missing)
+	}
+
+	return &schema.GetUsersResponse{
+		Users: users,
+	}, nil
+}
+
+func splitIDs(ids []string, size int) [][]string {
+	batches := make([][]string, 0, (len(ids)+size-1)/size)
+
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		batches = append(batches, ids[start:end])
+	}
+
+	return batches
+}
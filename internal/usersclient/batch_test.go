@@ -0,0 +1,43 @@
+package usersclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	schema "github.com/daniilty/sharenote-grpc-schema"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// failingUsersClient - fails batch starting with failID, other batches wait for cancellation.
+type failingUsersClient struct {
+	schema.UsersClient
+
+	failID string
+	err    error
+}
+
+func (f *failingUsersClient) GetUsers(ctx context.Context, in *schema.GetUsersRequest, _ ...grpc.CallOption) (*schema.GetUsersResponse, error) {
+	if in.GetIds()[0] == f.failID {
+		return nil, f.err
+	}
+
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
+func TestBatchedClientReturnsFirstError(t *testing.T) {
+	client := &failingUsersClient{
+		failID: "3",
+		err:    ErrCircuitOpen,
+	}
+
+	b := NewBatchedClient(client, zap.NewNop().Sugar(), 1, 4)
+
+	_, err := b.GetUsers(context.Background(), &schema.GetUsersRequest{Ids: []string{"1", "2", "3", "4"}})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got %v, want %v", err, ErrCircuitOpen)
+	}
+}
@@ -0,0 +1,139 @@
+package usersclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	schema "github.com/daniilty/sharenote-grpc-schema"
+	"google.golang.org/grpc"
+)
+
+var _ schema.UsersClient = (*CachedClient)(nil)
+
+// Cache - user profiles cache backend.
+type Cache interface {
+	// GetMany - get cached users, missing ids are absent from result.
+	GetMany(context.Context, []string) (map[string]*schema.User, error)
+	// SetMany - cache users.
+	SetMany(context.Context, []*schema.User) error
+	// Delete - drop users from cache.
+	Delete(context.Context, ...string) error
+}
+
+// Invalidator - drops stale user profiles.
+type Invalidator interface {
+	// Invalidate - drop users from cache.
+	Invalidate(context.Context, ...string) error
+}
+
+// CachedClient - users client decorator caching GetUsers results.
+type CachedClient struct {
+	schema.UsersClient
+
+	cache Cache
+}
+
+// NewCachedClient - CachedClient constructor.
+func NewCachedClient(client schema.UsersClient, cache Cache) *CachedClient {
+	return &CachedClient{
+		UsersClient: client,
+		cache:       cache,
+	}
+}
+
+// GetUsers - get users from cache and fetch only missing ones.
+func (c *CachedClient) GetUsers(ctx context.Context, in *schema.GetUsersRequest, opts ...grpc.CallOption) (*schema.GetUsersResponse, error) {
+	ids := in.GetIds()
+
+	cached, err := c.cache.GetMany(ctx, ids)
+	if err != nil {
+		// cache is an optimisation, fall back to users service
+		cached = map[string]*schema.User{}
+	}
+
+	missing := make([]string, 0, len(ids))
+
+	for i := range ids {
+		if _, ok := cached[ids[i]]; !ok {
+			missing = append(missing, ids[i])
+		}
+	}
+
+	if len(missing) > 0 {
+		resp, err := c.UsersClient.GetUsers(ctx, &schema.GetUsersRequest{
+			Ids: missing,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		fetched := resp.GetUsers()
+
+		// failing to fill cache must not fail request
+		_ = c.cache.SetMany(ctx, fetched)
+
+		for i := range fetched {
+			cached[fetched[i].GetId()] = fetched[i]
+		}
+	}
+
+	users := make([]*schema.User, 0, len(ids))
+
+	for i := range ids {
+		u, ok := cached[ids[i]]
+		if !ok {
+			continue
+		}
+
+		users = append(users, u)
+	}
+
+	return &schema.GetUsersResponse{
+		Users: users,
+	}, nil
+}
+
+func (c *CachedClient) Invalidate(ctx context.Context, ids ...string) error {
+	return c.cache.Delete(ctx, ids...)
+}
+
+const (
+	// CacheBackendNone - caching disabled.
+	CacheBackendNone = "none"
+	// CacheBackendMemory - in-process LRU cache.
+	CacheBackendMemory = "memory"
+	// CacheBackendRedis - redis cache.
+	CacheBackendRedis = "redis"
+)
+
+// CacheConfig - users cache config.
+type CacheConfig struct {
+	Backend string
+	Size    int
+	TTL     time.Duration
+}
+
+// Validate - check config values.
+func (c *CacheConfig) Validate() error {
+	switch c.Backend {
+	case CacheBackendNone, CacheBackendMemory, CacheBackendRedis:
+	default:
+		return fmt.Errorf("users cache: unsupported backend %q", c.Backend)
+	}
+
+	if c.Backend == CacheBackendNone {
+		return nil
+	}
+
+	if c.TTL <= 0 {
+		return errors.New("users cache: ttl must be positive")
+	}
+
+	if c.Backend == CacheBackendMemory && c.Size <= 0 {
+		return errors.New("users cache: size must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package usersclient
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	schema "github.com/daniilty/sharenote-grpc-schema"
+)
+
+var _ Cache = (*LRUCache)(nil)
+
+type lruEntry struct {
+	user      *schema.User
+	expiresAt time.Time
+}
+
+// LRUCache - in-process LRU cache with TTL.
+// Every replica keeps its own copy, so invalidation events consumed by one
+// replica do not reach the others, use redis cache when running several replicas.
+type LRUCache struct {
+	mu sync.Mutex
+
+	size int
+	ttl  time.Duration
+
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// NewLRUCache - LRUCache constructor.
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	return &LRUCache{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+func (l *LRUCache) GetMany(ctx context.Context, ids []string) (map[string]*schema.User, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	users := make(map[string]*schema.User, len(ids))
+
+	for i := range ids {
+		el, ok := l.items[ids[i]]
+		if !ok {
+			continue
+		}
+
+		entry := el.Value.(*lruEntry)
+		if now.After(entry.expiresAt) {
+			l.remove(el)
+
+			continue
+		}
+
+		l.ll.MoveToFront(el)
+		users[ids[i]] = entry.user
+	}
+
+	return users, nil
+}
+
+func (l *LRUCache) SetMany(ctx context.Context, users []*schema.User) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expiresAt := time.Now().Add(l.ttl)
+
+	for i := range users {
+		id := users[i].GetId()
+
+		el, ok := l.items[id]
+		if ok {
+			el.Value = &lruEntry{user: users[i], expiresAt: expiresAt}
+			l.ll.MoveToFront(el)
+
+			continue
+		}
+
+		l.items[id] = l.ll.PushFront(&lruEntry{user: users[i], expiresAt: expiresAt})
+
+		if l.ll.Len() > l.size {
+			l.remove(l.ll.Back())
+		}
+	}
+
+	return nil
+}
+
+func (l *LRUCache) Delete(ctx context.Context, ids ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range ids {
+		el, ok := l.items[ids[i]]
+		if ok {
+			l.remove(el)
+		}
+	}
+
+	return nil
+}
+
+func (l *LRUCache) remove(el *list.Element) {
+	entry := l.ll.Remove(el).(*lruEntry)
+	delete(l.items, entry.user.GetId())
+}
@@ -0,0 +1,21 @@
+package usersclient
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	usersGRPCRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "friends_users_grpc_requests_total",
+		Help: "Users service gRPC call attempts by method and status code.",
+	}, []string{"method", "code"})
+	usersGRPCRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "friends_users_grpc_request_duration_seconds",
+		Help: "Users service gRPC call attempt latency by method.",
+	}, []string{"method"})
+	usersGRPCCircuitOpenTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "friends_users_grpc_circuit_open_total",
+		Help: "Users service calls rejected by open circuit breaker.",
+	}, []string{"method"})
+)
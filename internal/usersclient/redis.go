@@ -0,0 +1,98 @@
+package usersclient
+
+import (
+	"context"
+	"time"
+
+	schema "github.com/daniilty/sharenote-grpc-schema"
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ Cache = (*RedisCache)(nil)
+
+const redisKeyPrefix = "friends:users:"
+
+// RedisCache - redis cache shared by every replica.
+type RedisCache struct {
+	redis redis.Cmdable
+	ttl   time.Duration
+}
+
+// NewRedisCache - RedisCache constructor.
+func NewRedisCache(r redis.Cmdable, ttl time.Duration) *RedisCache {
+	return &RedisCache{
+		redis: r,
+		ttl:   ttl,
+	}
+}
+
+func (r *RedisCache) GetMany(ctx context.Context, ids []string) (map[string]*schema.User, error) {
+	users := make(map[string]*schema.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	values, err := r.redis.MGet(ctx, redisKeys(ids)...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range values {
+		// missing keys are nil
+		raw, ok := values[i].(string)
+		if !ok {
+			continue
+		}
+
+		u := &schema.User{}
+
+		err = proto.Unmarshal([]byte(raw), u)
+		if err != nil {
+			continue
+		}
+
+		users[u.GetId()] = u
+	}
+
+	return users, nil
+}
+
+func (r *RedisCache) SetMany(ctx context.Context, users []*schema.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	pipe := r.redis.Pipeline()
+
+	for i := range users {
+		bb, err := proto.Marshal(users[i])
+		if err != nil {
+			return err
+		}
+
+		pipe.Set(ctx, redisKeyPrefix+users[i].GetId(), bb, r.ttl)
+	}
+
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+func (r *RedisCache) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return r.redis.Del(ctx, redisKeys(ids)...).Err()
+}
+
+func redisKeys(ids []string) []string {
+	keys := make([]string, 0, len(ids))
+
+	for i := range ids {
+		keys = append(keys, redisKeyPrefix+ids[i])
+	}
+
+	return keys
+}
@@ -0,0 +1,70 @@
+package usersclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	schema "github.com/daniilty/sharenote-grpc-schema"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer client.Close()
+
+	cache := NewRedisCache(client, time.Minute)
+
+	err := cache.SetMany(ctx, []*schema.User{{Id: "1", Name: "one"}, {Id: "2", Name: "two"}})
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	if got := srv.TTL(redisKeyPrefix + "1"); got != time.Minute {
+		t.Fatalf("got ttl %s, want 1m", got)
+	}
+
+	// garbage values are treated as missing
+	err = srv.Set(redisKeyPrefix+"3", "\xff")
+	if err != nil {
+		t.Fatalf("set garbage: %v", err)
+	}
+
+	users, err := cache.GetMany(ctx, []string{"1", "2", "3", "4"})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	if len(users) != 2 || users["1"].GetName() != "one" || users["2"].GetName() != "two" {
+		t.Fatalf("got %v, want users 1 and 2", users)
+	}
+
+	err = cache.Delete(ctx, "1")
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	users, err = cache.GetMany(ctx, []string{"1", "2"})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	if _, ok := users["1"]; ok || len(users) != 1 {
+		t.Fatalf("got %v, want only user 2", users)
+	}
+
+	srv.FastForward(time.Minute)
+
+	users, err = cache.GetMany(ctx, []string{"2"})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	if len(users) != 0 {
+		t.Fatalf("got %v after ttl, want none", users)
+	}
+}
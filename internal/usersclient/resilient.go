@@ -0,0 +1,245 @@
+package usersclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	schema "github.com/daniilty/sharenote-grpc-schema"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ schema.UsersClient = (*ResilientClient)(nil)
+
+// ErrCircuitOpen - users service is considered degraded and calls are not attempted.
+var ErrCircuitOpen = status.Error(codes.Unavailable, "users service circuit breaker is open")
+
+// Config - resilient client config.
+type Config struct {
+	// Timeout - per attempt deadline.
+	Timeout time.Duration
+	// MaxRetries - retries of idempotent calls after first attempt.
+	MaxRetries int
+	// BaseBackoff - first retry backoff cap, doubled on each retry.
+	BaseBackoff time.Duration
+	// MaxBackoff - retry backoff cap.
+	MaxBackoff time.Duration
+	// BreakerThreshold - consecutive failures to open circuit.
+	BreakerThreshold int
+	// BreakerOpenTimeout - time before probing users service again.
+	BreakerOpenTimeout time.Duration
+	// BatchSize - max ids per GetUsers call.
+	BatchSize int
+	// BatchConcurrency - max concurrent GetUsers batches.
+	BatchConcurrency int
+}
+
+// Validate - check config values.
+func (c *Config) Validate() error {
+	switch {
+	case c.Timeout <= 0:
+		return errors.New("users client: timeout must be positive")
+	case c.MaxRetries < 0:
+		return errors.New("users client: max retries cannot be negative")
+	case c.BaseBackoff <= 0 || c.MaxBackoff < c.BaseBackoff:
+		return errors.New("users client: backoff must be positive and base backoff must not exceed max backoff")
+	case c.BreakerThreshold <= 0:
+		return errors.New("users client: breaker threshold must be positive")
+	case c.BreakerOpenTimeout <= 0:
+		return errors.New("users client: breaker open timeout must be positive")
+	case c.BatchSize <= 0:
+		return errors.New("users client: batch size must be positive")
+	case c.BatchConcurrency <= 0:
+		return errors.New("users client: batch concurrency must be positive")
+	}
+
+	return nil
+}
+
+// ResilientClient - users client decorator with deadlines, retries and circuit breaking.
+type ResilientClient struct {
+	client  schema.UsersClient
+	cfg     Config
+	breaker *breaker
+}
+
+// NewResilientClient - ResilientClient constructor.
+func NewResilientClient(client schema.UsersClient, cfg Config) *ResilientClient {
+	return &ResilientClient{
+		client:  client,
+		cfg:     cfg,
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerOpenTimeout),
+	}
+}
+
+func (r *ResilientClient) AddUser(ctx context.Context, in *schema.AddUserRequest, opts ...grpc.CallOption) (*schema.AddUserResponse, error) {
+	var resp *schema.AddUserResponse
+
+	err := r.call(ctx, "AddUser", false, func(ctx context.Context) error {
+		var err error
+
+		resp, err = r.client.AddUser(ctx, in, opts...)
+
+		return err
+	})
+
+	return resp, err
+}
+
+func (r *ResilientClient) GetUser(ctx context.Context, in *schema.GetUserRequest, opts ...grpc.CallOption) (*schema.GetUserResponse, error) {
+	var resp *schema.GetUserResponse
+
+	err := r.call(ctx, "GetUser", true, func(ctx context.Context) error {
+		var err error
+
+		resp, err = r.client.GetUser(ctx, in, opts...)
+
+		return err
+	})
+
+	return resp, err
+}
+
+func (r *ResilientClient) GetUserByEmail(ctx context.Context, in *schema.GetUserByEmailRequest, opts ...grpc.CallOption) (*schema.GetUserByEmailResponse, error) {
+	var resp *schema.GetUserByEmailResponse
+
+	err := r.call(ctx, "GetUserByEmail", true, func(ctx context.Context) error {
+		var err error
+
+		resp, err = r.client.GetUserByEmail(ctx, in, opts...)
+
+		return err
+	})
+
+	return resp, err
+}
+
+func (r *ResilientClient) IsValidUserCredentials(ctx context.Context, in *schema.IsValidUserCredentialsRequest, opts ...grpc.CallOption) (*schema.IsValidUserCredentialsResponse, error) {
+	var resp *schema.IsValidUserCredentialsResponse
+
+	err := r.call(ctx, "IsValidUserCredentials", true, func(ctx context.Context) error {
+		var err error
+
+		resp, err = r.client.IsValidUserCredentials(ctx, in, opts...)
+
+		return err
+	})
+
+	return resp, err
+}
+
+func (r *ResilientClient) GetUsers(ctx context.Context, in *schema.GetUsersRequest, opts ...grpc.CallOption) (*schema.GetUsersResponse, error) {
+	var resp *schema.GetUsersResponse
+
+	err := r.call(ctx, "GetUsers", true, func(ctx context.Context) error {
+		var err error
+
+		resp, err = r.client.GetUsers(ctx, in, opts...)
+
+		return err
+	})
+
+	return resp, err
+}
+
+func (r *ResilientClient) UpdateUser(ctx context.Context, in *schema.UpdateUserRequest, opts ...grpc.CallOption) (*schema.UpdateUserResponse, error) {
+	var resp *schema.UpdateUserResponse
+
+	err := r.call(ctx, "UpdateUser", false, func(ctx context.Context) error {
+		var err error
+
+		resp, err = r.client.UpdateUser(ctx, in, opts...)
+
+		return err
+	})
+
+	return resp, err
+}
+
+func (r *ResilientClient) call(ctx context.Context, method string, idempotent bool, fn func(context.Context) error) error {
+	maxAttempts := 1
+	if idempotent {
+		maxAttempts += r.cfg.MaxRetries
+	}
+
+	var err error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %s", ctx.Err(), err)
+			case <-time.After(backoff(attempt-1, r.cfg.BaseBackoff, r.cfg.MaxBackoff)):
+			}
+		}
+
+		if !r.breaker.allow() {
+			usersGRPCCircuitOpenTotal.WithLabelValues(method).Inc()
+
+			return ErrCircuitOpen
+		}
+
+		err = r.attempt(ctx, method, fn)
+		if err == nil {
+			r.breaker.success()
+
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			r.breaker.abort()
+
+			return err
+		}
+
+		if !isFailure(err) {
+			r.breaker.success()
+
+			return err
+		}
+
+		r.breaker.failure()
+
+		if !isRetryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (r *ResilientClient) attempt(ctx context.Context, method string, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	err := fn(ctx)
+
+	usersGRPCRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	usersGRPCRequestsTotal.WithLabelValues(method, status.Code(err).String()).Inc()
+
+	return err
+}
+
+// isFailure - error means users service is unhealthy rather than request is invalid.
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
+
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}